@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"time"
+)
+
+// calibrationFlags are flags which affect results of calibration
+var calibrationFlags = []string{"m", "h", "b", "A", "T", "t", "k", "disable-compression", "successStatusCode", "calibrateLatency", "latencyWindow",
+	"burstDuration", "adjustmentDuration", "burstErrorRate", "backoffErrorRate", "multiplier", "multiplierDecay",
+	"search", "searchStart", "searchStep", "searchHold", "searchRefine", "sloLatency", "sloErrors"}
+
+// savedCalibration is a loadConfig stored to file
+// along with conditions it was measured at
+type savedCalibration struct {
+	Qps      float64           `json:"qps"`
+	Workers  int               `json:"workers"`
+	URL      string            `json:"url"`
+	Settings map[string]string `json:"settings"`
+	Time     time.Time         `json:"time"`
+}
+
+func currentSettings() map[string]string {
+	settings := make(map[string]string, len(calibrationFlags))
+	for _, name := range calibrationFlags {
+		if f := flag.Lookup(name); f != nil {
+			settings[name] = f.Value.String()
+		}
+	}
+	return settings
+}
+
+// saveCalibration stores cfg to calibration file, if it was set
+func saveCalibration(cfg *loadConfig) {
+	if *calibration == "" {
+		return
+	}
+
+	sc := savedCalibration{
+		Qps:      cfg.qps,
+		Workers:  cfg.c,
+		URL:      req.URI().String(),
+		Settings: currentSettings(),
+		Time:     time.Now(),
+	}
+	data, err := json.MarshalIndent(sc, "", "  ")
+	if err != nil {
+		log.Fatalf("Error while encoding calibration: %s", err)
+	}
+	if err := ioutil.WriteFile(*calibration, data, 0644); err != nil {
+		log.Fatalf("Error while trying to save calibration: %s", err)
+	}
+	fmt.Printf("Calibration saved to %q\n", *calibration)
+}
+
+// restoreCalibration populates cfg from calibration file
+// returns false if file wasn't set or doesn't exist yet
+func restoreCalibration(cfg *loadConfig) bool {
+	if *calibration == "" {
+		return false
+	}
+
+	data, err := ioutil.ReadFile(*calibration)
+	if os.IsNotExist(err) {
+		return false
+	}
+	if err != nil {
+		log.Fatalf("Error while trying to read calibration: %s", err)
+	}
+	var sc savedCalibration
+	if err := json.Unmarshal(data, &sc); err != nil {
+		log.Fatalf("Error while parsing calibration %q: %s", *calibration, err)
+	}
+
+	fmt.Printf("Calibration restored from %q, measured at %s: QPS %.2f; Workers: %d\n", *calibration, sc.Time.Format(time.RFC3339), sc.Qps, sc.Workers)
+	if url := req.URI().String(); url != sc.URL {
+		fmt.Printf("Warning: calibration was measured against %q, but target is %q\n", sc.URL, url)
+	}
+	settings := currentSettings()
+	for _, name := range calibrationFlags {
+		if saved, ok := sc.Settings[name]; ok && saved != settings[name] {
+			fmt.Printf("Warning: calibration was measured with -%s=%q, but current value is %q\n", name, saved, settings[name])
+		}
+	}
+
+	cfg.qps = sc.Qps
+	cfg.c = sc.Workers
+	return true
+}
@@ -0,0 +1,244 @@
+package fastclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/valyala/fasthttp"
+)
+
+var assertions assertionList
+
+func init() {
+	flag.Var(&assertions, "assert", "Assertion on response, could be passed multiple times. Supported assertions: "+
+		"status:200-299,304 - status code is in set; header:Name or header:Name=value - header is present or equal to value; "+
+		"body:text - body contains text; regex:expr - body matches regular expression; "+
+		"json:path=value - value by path (e.g. data.items.0.id) equals to JSON value; maxBody:N - decompressed body is not bigger than N bytes. "+
+		"Assertions are checked for every received response. Request with failed assertion isn't considered as successful")
+}
+
+// assertion checks response
+type assertion struct {
+	// name is an assertion as it was passed in flag
+	name  string
+	check func(r *assertedResponse) bool
+}
+
+// assertedResponse caches decoded body between assertions
+type assertedResponse struct {
+	resp *fasthttp.Response
+	body []byte
+
+	jsonParsed bool
+	json       interface{}
+}
+
+func (r *assertedResponse) getBody() []byte {
+	if r.body == nil {
+		b, err := r.resp.BodyUncompressed()
+		if err != nil {
+			b = r.resp.Body()
+		}
+		r.body = b
+	}
+	return r.body
+}
+
+func (r *assertedResponse) getJSON() (interface{}, bool) {
+	if !r.jsonParsed {
+		r.jsonParsed = true
+		if err := json.Unmarshal(r.getBody(), &r.json); err != nil {
+			r.json = nil
+		}
+	}
+	return r.json, r.json != nil
+}
+
+// assertionList is a list of assertions passed via flags
+type assertionList []assertion
+
+// String implements flag.Value
+func (al *assertionList) String() string {
+	var names []string
+	for _, a := range *al {
+		names = append(names, a.name)
+	}
+	return strings.Join(names, " ")
+}
+
+// Set implements flag.Value
+func (al *assertionList) Set(value string) error {
+	a, err := parseAssertion(value)
+	if err != nil {
+		return err
+	}
+	*al = append(*al, a)
+	return nil
+}
+
+// check checks response against all assertions
+// and counts every failed assertion by its name
+func (al assertionList) check(resp *fasthttp.Response) bool {
+	r := &assertedResponse{resp: resp}
+	ok := true
+	for _, a := range al {
+		if !a.check(r) {
+			assertionFailures.With(prometheus.Labels{"assertion": a.name}).Inc()
+			ok = false
+		}
+	}
+	return ok
+}
+
+func parseAssertion(value string) (assertion, error) {
+	n := strings.Index(value, ":")
+	if n < 0 {
+		return assertion{}, fmt.Errorf("cannot parse assertion %q; expected format is kind:argument", value)
+	}
+	kind, arg := value[:n], value[n+1:]
+	a := assertion{name: value}
+	switch kind {
+	case "status":
+		sr, err := parseStatusRanges(arg)
+		if err != nil {
+			return a, err
+		}
+		a.check = func(r *assertedResponse) bool {
+			return sr.contains(r.resp.StatusCode())
+		}
+	case "header":
+		name, expected := arg, ""
+		hasValue := false
+		if i := strings.Index(arg, "="); i >= 0 {
+			name, expected, hasValue = arg[:i], arg[i+1:], true
+		}
+		a.check = func(r *assertedResponse) bool {
+			v := r.resp.Header.Peek(name)
+			if !hasValue {
+				return v != nil
+			}
+			return string(v) == expected
+		}
+	case "body":
+		text := []byte(arg)
+		a.check = func(r *assertedResponse) bool {
+			return bytes.Contains(r.getBody(), text)
+		}
+	case "regex":
+		re, err := regexp.Compile(arg)
+		if err != nil {
+			return a, fmt.Errorf("cannot parse regex assertion %q: %s", value, err)
+		}
+		a.check = func(r *assertedResponse) bool {
+			return re.Match(r.getBody())
+		}
+	case "json":
+		i := strings.Index(arg, "=")
+		if i < 0 {
+			return a, fmt.Errorf("cannot parse json assertion %q; expected format is json:path=value", value)
+		}
+		path := splitJSONPath(arg[:i])
+		var expected interface{}
+		if err := json.Unmarshal([]byte(arg[i+1:]), &expected); err != nil {
+			// treat value as string if it isn't valid JSON
+			expected = arg[i+1:]
+		}
+		a.check = func(r *assertedResponse) bool {
+			doc, ok := r.getJSON()
+			if !ok {
+				return false
+			}
+			v, ok := lookupJSONPath(doc, path)
+			return ok && reflect.DeepEqual(v, expected)
+		}
+	case "maxBody":
+		max, err := strconv.Atoi(arg)
+		if err != nil {
+			return a, fmt.Errorf("cannot parse maxBody assertion %q: %s", value, err)
+		}
+		a.check = func(r *assertedResponse) bool {
+			return len(r.getBody()) <= max
+		}
+	default:
+		return a, fmt.Errorf("unsupported assertion %q; supported kinds are status, header, body, regex, json and maxBody", kind)
+	}
+	return a, nil
+}
+
+// splitJSONPath splits path like data.items[0].id
+// or $.data.items.0.id into keys
+func splitJSONPath(path string) []string {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	path = strings.NewReplacer("[", ".", "]", "").Replace(path)
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, ".")
+}
+
+// lookupJSONPath returns value of decoded JSON document by path
+func lookupJSONPath(doc interface{}, path []string) (interface{}, bool) {
+	for _, key := range path {
+		switch v := doc.(type) {
+		case map[string]interface{}:
+			var ok bool
+			if doc, ok = v[key]; !ok {
+				return nil, false
+			}
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			doc = v[i]
+		default:
+			return nil, false
+		}
+	}
+	return doc, true
+}
+
+// statusRanges is a set of status codes ranges
+type statusRanges [][2]int
+
+// parseStatusRanges parses comma-separated list
+// of status codes and ranges like 200-299,304
+func parseStatusRanges(s string) (statusRanges, error) {
+	var sr statusRanges
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		bounds := strings.SplitN(part, "-", 2)
+		from, err := strconv.Atoi(bounds[0])
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse status code %q: %s", part, err)
+		}
+		to := from
+		if len(bounds) == 2 {
+			if to, err = strconv.Atoi(bounds[1]); err != nil {
+				return nil, fmt.Errorf("cannot parse status codes range %q: %s", part, err)
+			}
+		}
+		if to < from {
+			return nil, fmt.Errorf("wrong status codes range %q", part)
+		}
+		sr = append(sr, [2]int{from, to})
+	}
+	return sr, nil
+}
+
+// contains returns true if code is in one of ranges
+func (sr statusRanges) contains(code int) bool {
+	for _, r := range sr {
+		if code >= r[0] && code <= r[1] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package fastclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestAssertions(t *testing.T) {
+	resp := new(fasthttp.Response)
+	resp.SetStatusCode(fasthttp.StatusCreated)
+	resp.Header.Set("X-Request-Id", "42")
+	resp.SetBodyString(`{"status": "ok", "data": {"items": [{"id": 1}, {"id": 2}]}}`)
+
+	f := func(value string, expected bool) {
+		t.Helper()
+		a, err := parseAssertion(value)
+		if err != nil {
+			t.Fatalf("Unexpected error for %q: %s", value, err)
+		}
+		if ok := a.check(&assertedResponse{resp: resp}); ok != expected {
+			t.Errorf("Unexpected result of %q. Got: %v; Expected: %v", value, ok, expected)
+		}
+	}
+	f("status:200-299", true)
+	f("status:200,304", false)
+	f("header:X-Request-Id", true)
+	f("header:X-Request-Id=43", false)
+	f("header:X-Missing", false)
+	f("body:items", true)
+	f(`regex:"id":\s*3`, false)
+	f(`json:status="ok"`, true)
+	f("json:status=ok", true)
+	f("json:data.items[1].id=2", true)
+	f("json:data.items.2.id=2", false)
+	f("maxBody:10", false)
+
+	for _, v := range []string{"status", "status:abc", "status:300-200", "regex:(", "json:status", "maxBody:x", "unknown:1"} {
+		if _, err := parseAssertion(v); err == nil {
+			t.Errorf("Expected error for %q", v)
+		}
+	}
+}
+
+func TestAssertionsOfFailedStatus(t *testing.T) {
+	a, err := parseAssertion("body:ok")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	assertions = assertionList{a}
+	defer func() { assertions = nil }()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	req := new(fasthttp.Request)
+	req.SetRequestURI(srv.URL)
+	c, err := New(req, time.Second, "200")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	c.RunWorkers(1)
+	defer c.Flush()
+	defer c.transport.(*fasthttp.HostClient).CloseIdleConnections()
+	before := c.AssertionFailures()["body:ok"]
+	c.Jobsch <- struct{}{}
+	waitRequests(t, c, 1)
+
+	// assertion is checked even if status code isn't successful
+	if n := c.AssertionFailures()["body:ok"] - before; n != 1 {
+		t.Errorf("Unexpected number of assertion failures. Got: %v; Expected: %d", n, 1)
+	}
+}
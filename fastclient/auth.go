@@ -0,0 +1,481 @@
+package fastclient
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+var (
+	authKind = flag.String("auth", "", "Attach credentials to every request. Supported providers: "+
+		"oauth2 - bearer token obtained by client credentials grant; sigv4 - AWS Signature Version 4 with credentials "+
+		"from AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN env vars; jwt - bearer JWT signed by HS256 for every request")
+
+	oauth2TokenURL     = flag.String("oauth2TokenUrl", "", "Token endpoint of OAuth2 provider")
+	oauth2ClientID     = flag.String("oauth2ClientId", "", "OAuth2 client id")
+	oauth2ClientSecret = flag.String("oauth2ClientSecret", "", "OAuth2 client secret. OAUTH2_CLIENT_SECRET env var is used if not set")
+	oauth2Scope        = flag.String("oauth2Scope", "", "Space-separated list of OAuth2 scopes")
+
+	awsRegion  = flag.String("awsRegion", "us-east-1", "AWS region for SigV4 signature")
+	awsService = flag.String("awsService", "execute-api", "AWS service for SigV4 signature, e.g. execute-api, es or s3")
+
+	jwtSecret = flag.String("jwtSecret", "", "Secret for HS256 signature of JWT. JWT_SECRET env var is used if not set")
+	jwtClaims = flag.String("jwtClaims", "{}", `JSON object with JWT claims, e.g. {"sub":"loadtest","aud":"api"}. `+
+		"Claims iat, exp and jti are set for every request")
+	jwtTTL = flag.Duration("jwtTTL", time.Minute, "Lifetime of JWT")
+)
+
+// tokenRetryInterval is a pause between failed token requests
+const tokenRetryInterval = time.Second
+
+// authProvider attaches credentials to request
+type authProvider interface {
+	sign(req *fasthttp.Request) error
+}
+
+// refresher is implemented by providers which can obtain
+// new credentials after request was rejected
+type refresher interface {
+	// refresh obtains new credentials if credentials of req are still current
+	refresh(req *fasthttp.Request) error
+}
+
+// oauth2Tokens is shared between clients,
+// so token isn't requested again at every phase of test
+var (
+	oauth2Once   sync.Once
+	oauth2Tokens *tokenSource
+	oauth2Err    error
+)
+
+// newAuthProvider returns provider set by -auth flag
+// or nil if flag isn't set
+func newAuthProvider() (authProvider, error) {
+	switch *authKind {
+	case "":
+		return nil, nil
+	case "oauth2":
+		oauth2Once.Do(func() {
+			secret := *oauth2ClientSecret
+			if secret == "" {
+				secret = os.Getenv("OAUTH2_CLIENT_SECRET")
+			}
+			var tlsConfig *tls.Config
+			if tlsConfig, oauth2Err = newTLSConfig(); oauth2Err != nil {
+				return
+			}
+			oauth2Tokens = newTokenSource(*oauth2TokenURL, *oauth2ClientID, secret, *oauth2Scope)
+			oauth2Tokens.client = newTokenClient(tlsConfig)
+			if oauth2Err = oauth2Tokens.fetch(); oauth2Err == nil {
+				go oauth2Tokens.run(nil)
+			}
+		})
+		if oauth2Err != nil {
+			return nil, oauth2Err
+		}
+		return oauth2Tokens, nil
+	case "sigv4":
+		s := &sigV4{
+			accessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+			secretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			sessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+			region:       *awsRegion,
+			service:      *awsService,
+			now:          time.Now,
+		}
+		if s.accessKey == "" || s.secretKey == "" {
+			return nil, fmt.Errorf("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY env vars must be set for sigv4 auth")
+		}
+		return s, nil
+	case "jwt":
+		secret := *jwtSecret
+		if secret == "" {
+			secret = os.Getenv("JWT_SECRET")
+		}
+		return newJWTSigner(secret, *jwtClaims, *jwtTTL)
+	}
+	return nil, fmt.Errorf("unsupported auth provider %q; supported providers are oauth2, sigv4 and jwt", *authKind)
+}
+
+// authTransport signs every request. Requests rejected with 401 or 403
+// are retried once with refreshed credentials if provider supports it,
+// and counted as auth failures if they are rejected again
+type authTransport struct {
+	t Transport
+	p authProvider
+}
+
+// Do signs and sends req
+func (a *authTransport) Do(req *fasthttp.Request, resp *fasthttp.Response) error {
+	if err := a.p.sign(req); err != nil {
+		resp.Reset()
+		authFailures.Inc()
+		return fmt.Errorf("auth: %s", err)
+	}
+	err := a.t.Do(req, resp)
+	if err != nil || !isAuthFailure(resp.StatusCode()) {
+		return err
+	}
+	if r, ok := a.p.(refresher); ok {
+		if err := r.refresh(req); err != nil {
+			authFailures.Inc()
+			return fmt.Errorf("auth: %s", err)
+		}
+		if err := a.p.sign(req); err != nil {
+			authFailures.Inc()
+			return fmt.Errorf("auth: %s", err)
+		}
+		err = a.t.Do(req, resp)
+		if err != nil || !isAuthFailure(resp.StatusCode()) {
+			return err
+		}
+	}
+	authFailures.Inc()
+	return nil
+}
+
+func isAuthFailure(sc int) bool {
+	return sc == fasthttp.StatusUnauthorized || sc == fasthttp.StatusForbidden
+}
+
+// tokenSource obtains OAuth2 tokens by client credentials grant
+// and refreshes them in background before expiry,
+// so workers never wait for token
+type tokenSource struct {
+	url          string
+	clientID     string
+	clientSecret string
+	scope        string
+	client       *fasthttp.Client
+
+	// after is used for waiting till token refresh
+	after func(time.Duration) <-chan time.Time
+
+	mu     sync.RWMutex
+	token  string
+	expiry time.Time
+
+	// fetchMu serializes token requests
+	fetchMu sync.Mutex
+}
+
+func newTokenSource(tokenURL, clientID, clientSecret, scope string) *tokenSource {
+	return &tokenSource{
+		url:          tokenURL,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		scope:        scope,
+		client:       newTokenClient(&tls.Config{}),
+		after:        time.After,
+	}
+}
+
+// newTokenClient returns client for token requests, which connects through
+// the same proxy and with the same TLS settings as load.
+// Server name is taken from token url, since -tlsServerName is set for tested host
+func newTokenClient(tlsConfig *tls.Config) *fasthttp.Client {
+	tlsConfig = tlsConfig.Clone()
+	tlsConfig.ServerName = ""
+	return &fasthttp.Client{
+		Dial:      dialToken,
+		TLSConfig: tlsConfig,
+	}
+}
+
+// dialToken establishes connection to token endpoint.
+// Plain dialer is used, so token requests aren't counted
+// in DNS, connect and connections metrics of load
+func dialToken(addr string) (net.Conn, error) {
+	d := net.Dialer{
+		Deadline: time.Now().Add(*httpClientRequestTimeout),
+	}
+	if proxyURL == nil {
+		return d.Dial("tcp", addr)
+	}
+	conn, err := d.Dial("tcp", proxyURL.Host)
+	if err == nil {
+		conn, err = openTunnel(conn, addr, d.Deadline)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("proxy connect: %s", err)
+	}
+	return conn, nil
+}
+
+// sign implements authProvider
+func (ts *tokenSource) sign(req *fasthttp.Request) error {
+	ts.mu.RLock()
+	token := ts.token
+	ts.mu.RUnlock()
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// refresh implements refresher. Token is requested only if req was sent with current token,
+// so workers rejected at the same time cause single token request
+func (ts *tokenSource) refresh(req *fasthttp.Request) error {
+	ts.fetchMu.Lock()
+	defer ts.fetchMu.Unlock()
+	ts.mu.RLock()
+	current := "Bearer " + ts.token
+	ts.mu.RUnlock()
+	if string(req.Header.Peek("Authorization")) != current {
+		return nil
+	}
+	return ts.doFetch()
+}
+
+// run refreshes token when 80% of its lifetime has passed
+// till stop is closed. Tokens without expiry aren't refreshed in background
+func (ts *tokenSource) run(stop <-chan struct{}) {
+	for {
+		ts.mu.RLock()
+		expiry := ts.expiry
+		ts.mu.RUnlock()
+		if expiry.IsZero() {
+			return
+		}
+		d := time.Until(expiry) * 4 / 5
+		select {
+		case <-stop:
+			return
+		case <-ts.after(d):
+		}
+		for ts.fetch() != nil {
+			select {
+			case <-stop:
+				return
+			case <-ts.after(tokenRetryInterval):
+			}
+		}
+	}
+}
+
+func (ts *tokenSource) fetch() error {
+	ts.fetchMu.Lock()
+	defer ts.fetchMu.Unlock()
+	return ts.doFetch()
+}
+
+// doFetch requests new token. It must be called under fetchMu
+func (ts *tokenSource) doFetch() error {
+	token, expiresIn, err := ts.request()
+	if err != nil {
+		tokenErrors.Inc()
+		return fmt.Errorf("cannot obtain oauth2 token: %s", err)
+	}
+	tokenRefreshes.Inc()
+	ts.mu.Lock()
+	ts.token = token
+	ts.expiry = time.Time{}
+	if expiresIn > 0 {
+		ts.expiry = time.Now().Add(time.Duration(expiresIn) * time.Second)
+	}
+	ts.mu.Unlock()
+	return nil
+}
+
+func (ts *tokenSource) request() (string, int, error) {
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if ts.scope != "" {
+		form.Set("scope", ts.scope)
+	}
+	req.SetRequestURI(ts.url)
+	req.Header.SetMethod("POST")
+	req.Header.SetContentType("application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString(
+		[]byte(url.QueryEscape(ts.clientID)+":"+url.QueryEscape(ts.clientSecret))))
+	req.SetBodyString(form.Encode())
+	if err := ts.client.DoTimeout(req, resp, *httpClientRequestTimeout); err != nil {
+		return "", 0, err
+	}
+	if resp.StatusCode() != fasthttp.StatusOK {
+		return "", 0, fmt.Errorf("unexpected status code %d", resp.StatusCode())
+	}
+	var t struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.Unmarshal(resp.Body(), &t); err != nil {
+		return "", 0, fmt.Errorf("cannot parse token response: %s", err)
+	}
+	if t.AccessToken == "" {
+		return "", 0, fmt.Errorf("token response contains no access_token")
+	}
+	return t.AccessToken, t.ExpiresIn, nil
+}
+
+// sigV4 signs requests by AWS Signature Version 4
+type sigV4 struct {
+	accessKey    string
+	secretKey    string
+	sessionToken string
+	region       string
+	service      string
+	now          func() time.Time
+}
+
+// sign implements authProvider
+func (s *sigV4) sign(req *fasthttp.Request) error {
+	t := s.now().UTC()
+	amzDate := t.Format("20060102T150405Z")
+	date := t.Format("20060102")
+
+	payloadHash := sha256.Sum256(req.Body())
+	headers := map[string]string{
+		"x-amz-date": amzDate,
+	}
+	if s.service == "s3" {
+		headers["x-amz-content-sha256"] = hex.EncodeToString(payloadHash[:])
+	}
+	if s.sessionToken != "" {
+		headers["x-amz-security-token"] = s.sessionToken
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	host := req.Header.Host()
+	if len(host) == 0 {
+		host = req.URI().Host()
+	}
+	headers["host"] = string(host)
+
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + strings.TrimSpace(headers[k]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := string(req.URI().RequestURI())
+	if n := strings.IndexByte(path, '?'); n >= 0 {
+		path = path[:n]
+	}
+	if s.service != "s3" {
+		// path segments are encoded twice for all services except s3
+		path = awsEscape(path, false)
+	}
+	canonicalRequest := strings.Join([]string{
+		string(req.Header.Method()),
+		path,
+		canonicalQuery(req.URI().QueryArgs()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	scope := date + "/" + s.region + "/" + s.service + "/aws4_request"
+	crHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(crHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.secretKey), date)
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, s.service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+s.accessKey+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+	return nil
+}
+
+// canonicalQuery returns query args sorted by key and value
+func canonicalQuery(args *fasthttp.Args) string {
+	var pairs []string
+	args.VisitAll(func(k, v []byte) {
+		pairs = append(pairs, awsEscape(string(k), true)+"="+awsEscape(string(v), true))
+	})
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// awsEscape encodes all characters except unreserved ones.
+// Slash is encoded only if encodeSlash is set
+func awsEscape(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (c == '/' && !encodeSlash) {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// jwtSigner issues JWT signed by HS256 for every request
+type jwtSigner struct {
+	secret []byte
+	claims map[string]interface{}
+	ttl    time.Duration
+	header string
+	id     uint64
+}
+
+func newJWTSigner(secret, claims string, ttl time.Duration) (*jwtSigner, error) {
+	if secret == "" {
+		return nil, fmt.Errorf("jwtSecret or JWT_SECRET env var must be set for jwt auth")
+	}
+	j := &jwtSigner{
+		secret: []byte(secret),
+		ttl:    ttl,
+		header: base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`)),
+	}
+	if err := json.Unmarshal([]byte(claims), &j.claims); err != nil {
+		return nil, fmt.Errorf("cannot parse jwtClaims %q: %s", claims, err)
+	}
+	return j, nil
+}
+
+// sign implements authProvider
+func (j *jwtSigner) sign(req *fasthttp.Request) error {
+	now := time.Now()
+	claims := make(map[string]interface{}, len(j.claims)+3)
+	for k, v := range j.claims {
+		claims[k] = v
+	}
+	claims["iat"] = now.Unix()
+	claims["exp"] = now.Add(j.ttl).Unix()
+	claims["jti"] = strconv.FormatUint(atomic.AddUint64(&j.id, 1), 10)
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return err
+	}
+	token := j.header + "." + base64.RawURLEncoding.EncodeToString(payload)
+	token += "." + base64.RawURLEncoding.EncodeToString(hmacSHA256(j.secret, token))
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
@@ -0,0 +1,215 @@
+package fastclient
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// newTokenServer returns stub token endpoint, which issues
+// tokens token-1, token-2, ... with given lifetime
+func newTokenServer(expiresIn int) (*httptest.Server, *int32) {
+	var issued int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, secret, ok := r.BasicAuth()
+		if !ok || id != "client" || secret != "secret" || r.FormValue("grant_type") != "client_credentials" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		n := atomic.AddInt32(&issued, 1)
+		fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"bearer","expires_in":%d}`, n, expiresIn)
+	}))
+	return srv, &issued
+}
+
+// tokenTransport accepts only requests with given token
+type tokenTransport struct {
+	token string
+}
+
+func (tt *tokenTransport) Do(req *fasthttp.Request, resp *fasthttp.Response) error {
+	resp.Reset()
+	if string(req.Header.Peek("Authorization")) != "Bearer "+tt.token {
+		resp.SetStatusCode(fasthttp.StatusUnauthorized)
+	}
+	return nil
+}
+
+func TestTokenSource(t *testing.T) {
+	srv, issued := newTokenServer(100)
+	defer srv.Close()
+
+	ts := newTokenSource(srv.URL, "client", "wrong", "")
+	if err := ts.fetch(); err == nil {
+		t.Fatalf("Expected error for wrong client secret")
+	}
+
+	ts = newTokenSource(srv.URL, "client", "secret", "read")
+	// waits are reported to test, which decides when they end
+	waits := make(chan time.Duration)
+	ticks := make(chan time.Time)
+	ts.after = func(d time.Duration) <-chan time.Time {
+		waits <- d
+		return ticks
+	}
+	if err := ts.fetch(); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	stop := make(chan struct{})
+	go ts.run(stop)
+	defer close(stop)
+
+	// token is refreshed when 80% of its lifetime has passed
+	if d := <-waits; d <= 70*time.Second || d > 80*time.Second {
+		t.Fatalf("Unexpected wait before refresh. Got: %s; Expected: ~%s", d, 80*time.Second)
+	}
+	ticks <- time.Now()
+	// the next wait starts after token was refreshed
+	<-waits
+	if n := atomic.LoadInt32(issued); n != 2 {
+		t.Fatalf("Expected token to be refreshed in background. Got %d tokens", n)
+	}
+
+	// token is refreshed once after rejection,
+	// since server accepts only the next token
+	before := (*Client)(nil).AuthFailures()
+	tt := &tokenTransport{token: fmt.Sprintf("token-%d", atomic.LoadInt32(issued)+1)}
+	a := &authTransport{t: tt, p: ts}
+	req := new(fasthttp.Request)
+	resp := new(fasthttp.Response)
+	if err := a.Do(req, resp); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if resp.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Unexpected status code after refresh. Got: %d; Expected: %d", resp.StatusCode(), fasthttp.StatusOK)
+	}
+
+	// request rejected with fresh token is counted as auth failure
+	tt.token = "revoked"
+	if err := a.Do(req, resp); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if n := (*Client)(nil).AuthFailures() - before; n != 1 {
+		t.Errorf("Unexpected number of auth failures. Got: %d; Expected: %d", n, 1)
+	}
+}
+
+func TestSigV4(t *testing.T) {
+	// get-vanilla case of AWS SigV4 test suite
+	s := &sigV4{
+		accessKey: "AKIDEXAMPLE",
+		secretKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		region:    "us-east-1",
+		service:   "service",
+		now: func() time.Time {
+			return time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+		},
+	}
+	req := new(fasthttp.Request)
+	req.SetRequestURI("http://example.amazonaws.com/")
+	if err := s.sign(req); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	expected := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+		"SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if auth := string(req.Header.Peek("Authorization")); auth != expected {
+		t.Errorf("Unexpected Authorization header. Got: %q; Expected: %q", auth, expected)
+	}
+	if date := string(req.Header.Peek("X-Amz-Date")); date != "20150830T123600Z" {
+		t.Errorf("Unexpected X-Amz-Date header. Got: %q; Expected: %q", date, "20150830T123600Z")
+	}
+
+	if q := canonicalQuery(func() *fasthttp.Args {
+		req.SetRequestURI("http://example.amazonaws.com/?b=2&a=x y&a=1")
+		return req.URI().QueryArgs()
+	}()); q != "a=1&a=x%20y&b=2" {
+		t.Errorf("Unexpected canonical query. Got: %q; Expected: %q", q, "a=1&a=x%20y&b=2")
+	}
+}
+
+func TestJWT(t *testing.T) {
+	if _, err := newJWTSigner("", "{}", time.Minute); err == nil {
+		t.Errorf("Expected error for empty secret")
+	}
+	if _, err := newJWTSigner("secret", "[1]", time.Minute); err == nil {
+		t.Errorf("Expected error for wrong claims")
+	}
+
+	j, err := newJWTSigner("secret", `{"sub":"loadtest"}`, time.Minute)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	req := new(fasthttp.Request)
+	var ids []string
+	for i := 0; i < 2; i++ {
+		if err := j.sign(req); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		token := strings.TrimPrefix(string(req.Header.Peek("Authorization")), "Bearer ")
+		parts := strings.Split(token, ".")
+		if len(parts) != 3 {
+			t.Fatalf("Unexpected token %q", token)
+		}
+		sig := hex.EncodeToString(hmacSHA256([]byte("secret"), parts[0]+"."+parts[1]))
+		got, _ := base64.RawURLEncoding.DecodeString(parts[2])
+		if hex.EncodeToString(got) != sig {
+			t.Fatalf("Wrong signature of token %q", token)
+		}
+		payload, _ := base64.RawURLEncoding.DecodeString(parts[1])
+		var claims map[string]interface{}
+		if err := json.Unmarshal(payload, &claims); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if claims["sub"] != "loadtest" || claims["exp"].(float64)-claims["iat"].(float64) != 60 {
+			t.Errorf("Unexpected claims %v", claims)
+		}
+		ids = append(ids, claims["jti"].(string))
+	}
+	if ids[0] == ids[1] {
+		t.Errorf("Expected unique jti for every token. Got: %v", ids)
+	}
+}
+
+func TestTokenSourceDialer(t *testing.T) {
+	srv, _ := newTokenServer(100)
+	defer srv.Close()
+	// load dialer can't bind IPv4 connection to IPv6 address,
+	// so token request succeeds only if it is sent by plain dialer
+	if err := localAddrs.Set("::1"); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	defer func() { localAddrs = localAddrList{} }()
+
+	ts := newTokenSource(srv.URL, "client", "secret", "")
+	if err := ts.fetch(); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+}
+
+func TestTokenSourceProxy(t *testing.T) {
+	srv, _ := newTokenServer(100)
+	defer srv.Close()
+	tp := newTestProxy(t, false)
+	defer tp.ln.Close()
+	*proxyAddr = "http://user:pass@" + tp.ln.Addr().String()
+	defer func() { *proxyAddr, proxyURL = "", nil }()
+	if err := initProxy(); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	// token is requested through the same proxy as load,
+	// which refuses tunnels to hosts other than example.com
+	ts := newTokenSource(srv.URL, "client", "secret", "")
+	if err := ts.fetch(); err == nil || !strings.Contains(err.Error(), "proxy connect") {
+		t.Errorf("Expected token request to be sent through proxy. Got error: %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package fastclient
+
+import (
+	stderrors "errors"
+	"flag"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+var (
+	httpClientMaxRequestsPerConn = flag.Int("httpClientMaxRequestsPerConn", 0, "Max number of requests sent over one HTTP/1.1 connection. "+
+		"Connection is closed before the next request, which is sent over new connection. Zero means no limit")
+	connChurn = flag.Float64("connChurn", 0, "Number of new connections to open per second. "+
+		"Churn is made by sending requests with Connection: close at given rate, so connections are reopened by the next requests "+
+		"and churn can't exceed request rate")
+)
+
+// errConnExpired is returned on attempt to send request over connection,
+// which already served -httpClientMaxRequestsPerConn requests
+var errConnExpired = fmt.Errorf("connection served max number of requests")
+
+// retryIfErr retries requests failed because of expired connection,
+// since they weren't sent. Other requests are retried only if they are
+// idempotent, as fasthttp.HostClient does by default
+func retryIfErr(req *fasthttp.Request, attempts int, err error) (bool, bool) {
+	if stderrors.Is(err, errConnExpired) {
+		return false, true
+	}
+	return false, req.Header.IsGet() || req.Header.IsHead() || req.Header.IsPut()
+}
+
+// connCloser decides which requests should be sent with Connection: close
+// to make churn. It works for HTTP/1.1 only
+type connCloser struct {
+	mu   sync.Mutex
+	next time.Time
+}
+
+// newConnCloser returns nil if connections shouldn't be closed
+func newConnCloser() *connCloser {
+	if *connChurn <= 0 {
+		return nil
+	}
+	return &connCloser{}
+}
+
+// close returns true if next request should close connection
+func (cc *connCloser) close() bool {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	now := time.Now()
+	if now.Before(cc.next) {
+		return false
+	}
+	// do not catch up after pause to avoid bursts of new connections
+	if now.Sub(cc.next) > time.Second {
+		cc.next = now
+	}
+	cc.next = cc.next.Add(time.Duration(float64(time.Second) / *connChurn))
+	return true
+}
@@ -0,0 +1,75 @@
+package fastclient
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestMaxRequestsPerConn(t *testing.T) {
+	*httpClientMaxRequestsPerConn = 3
+	defer func() { *httpClientMaxRequestsPerConn = 0 }()
+	var conns, requests int32
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+	}))
+	srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	srv.Start()
+	defer srv.Close()
+
+	// non-idempotent requests must be sent over new connection as well
+	req := new(fasthttp.Request)
+	req.SetRequestURI(srv.URL)
+	req.Header.SetMethod("POST")
+	c, err := New(req, time.Second, "200")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	c.RunWorkers(2)
+	defer c.Flush()
+	// idle connections are closed before metrics are flushed
+	defer c.transport.(*fasthttp.HostClient).CloseIdleConnections()
+	for i := 0; i < 10; i++ {
+		c.Jobsch <- struct{}{}
+	}
+	waitRequests(t, c, 10)
+
+	if c.Errors() != 0 {
+		t.Fatalf("Unexpected errors: %v", c.ErrorMessages())
+	}
+	if n := atomic.LoadInt32(&requests); n != 10 {
+		t.Errorf("Unexpected number of requests. Got: %d; Expected: %d", n, 10)
+	}
+	// every connection serves at most 3 requests
+	if n := atomic.LoadInt32(&conns); n < 4 {
+		t.Errorf("Unexpected number of connections. Got: %d; Expected at least: %d", n, 4)
+	}
+}
+
+func TestConnChurn(t *testing.T) {
+	if newConnCloser() != nil {
+		t.Fatalf("Expected no closer without churn")
+	}
+	*connChurn = 10
+	defer func() { *connChurn = 0 }()
+	cc := newConnCloser()
+	if !cc.close() {
+		t.Errorf("Expected the first request to close connection")
+	}
+	if cc.close() {
+		t.Errorf("Expected request not to close connection before churn interval")
+	}
+	time.Sleep(110 * time.Millisecond)
+	if !cc.close() {
+		t.Errorf("Expected request to close connection after churn interval")
+	}
+}
@@ -1,7 +1,9 @@
 package fastclient
 
 import (
+	"crypto/tls"
 	"flag"
+	"fmt"
 	"io"
 	"log"
 	"net"
@@ -21,55 +23,217 @@ var (
 		"on keepalive connections. Zero disables keep-alive messages")
 	httpClientReadBufferSize  = flag.Int("httpClientReadBufferSize", 8*1024, "Per-connection read buffer size for httpclient")
 	httpClientWriteBufferSize = flag.Int("httpClientWriteBufferSize", 8*1024, "Per-connection write buffer size for httpclient")
+	httpClientMaxConns        = flag.Int("httpClientMaxConns", 0, "Max number of connections to server. "+
+		"Clients wait for free connection if limit is reached. Zero means no limit")
+	httpClientMaxIdleConnDuration = flag.Duration("httpClientMaxIdleConnDuration", time.Second, "Idle keep-alive connections are closed after this duration")
+	httpClientMaxConnDuration     = flag.Duration("httpClientMaxConnDuration", 0, "Keep-alive connections are closed after this duration. Zero means no limit")
+	latencyWindow                 = flag.Duration("latencyWindow", time.Second, "Period of time for which recent latency is calculated")
+	unixSocket                    = flag.String("unixSocket", "", "Path to Unix domain socket to connect to instead of url host. "+
+		"Host and path of url are still sent in requests")
 )
 
+// unixAddrPrefix is a prefix of Unix domain socket addresses
+const unixAddrPrefix = "unix:"
+
 const (
-	jobCapacity         = 10000
-	maxIdleConnDuration = time.Second
-	maxConns            = 1<<31 - 1
+	jobCapacity = 10000
+	maxConns    = 1<<31 - 1
 )
 
 func init() {
 	registerMetrics()
 }
 
-// Client is a wrapper for fasthttp.HostClient
+// Client is a wrapper for Transport
 // It allows to send requests and collect metrics while sending
 type Client struct {
 	// Jobsch is a channel of tasks(requests) which should be done
 	Jobsch chan struct{}
 
-	*fasthttp.HostClient
-	wg                sync.WaitGroup
-	request           *fasthttp.Request
-	successStatusCode int
+	transport          Transport
+	closer             *connCloser
+	redirecter         *redirecter
+	loginRequest       *fasthttp.Request
+	flow               *flow
+	script             *script
+	auth               authProvider
+	wg                 sync.WaitGroup
+	request            *fasthttp.Request
+	successStatusCodes statusRanges
 
 	sync.Mutex
 	workers          int
-	statusCodeLabels map[int]prometheus.Labels
+	statusCodeLabels map[string]prometheus.Labels
+	statusGroups     map[string]string
 	errorMessages    map[string]prometheus.Labels
 }
 
-// New creates new client
-func New(request *fasthttp.Request, timeout time.Duration, sc int) *Client {
+// New creates new client, which sends requests over HTTP/1.1,
+// HTTP/2 if -http2 flag is set, WebSocket for ws and wss urls,
+// gRPC calls if -grpcMethod flag is set or keeps event streams open
+// if -eventStream flag is set. Error is returned if url or flags
+// of transport are invalid
+func New(request *fasthttp.Request, timeout time.Duration, sc string) (*Client, error) {
+	addr, isTLS, err := acquireAddr(request)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse url: %s", err)
+	}
+	tlsConfig, err := newTLSConfig()
+	if err != nil {
+		return nil, fmt.Errorf("cannot init TLS config: %s", err)
+	}
+	if err := initProxy(); err != nil {
+		return nil, fmt.Errorf("cannot init proxy: %s", err)
+	}
+	if tlsConfig.ServerName == "" && *unixSocket != "" {
+		// dialTLS can't take server name from socket path
+		tlsConfig.ServerName = string(request.URI().Host())
+		if host, _, err := net.SplitHostPort(tlsConfig.ServerName); err == nil {
+			tlsConfig.ServerName = host
+		}
+	}
+	var t Transport
+	if isGRPC() {
+		gr, err := newGRPCRequest(request)
+		if err != nil {
+			return nil, fmt.Errorf("cannot init gRPC transport: %s", err)
+		}
+		request = gr
+		sc = strconv.Itoa(grpcStatusOK)
+		t = &grpcTransport{newHTTP2Client(addr, isTLS, timeout, tlsConfig)}
+	} else if isEventStream() {
+		et, err := newEventStreamTransport(timeout, tlsConfig)
+		if err != nil {
+			return nil, fmt.Errorf("cannot init event stream transport: %s", err)
+		}
+		t = et
+	} else if isWebsocket(request) {
+		wt, err := newWebsocketTransport(request, timeout, tlsConfig)
+		if err != nil {
+			return nil, fmt.Errorf("cannot init WebSocket transport: %s", err)
+		}
+		t = wt
+	} else if *http2Enabled {
+		t = newHTTP2Client(addr, isTLS, timeout, tlsConfig)
+	} else {
+		newHostClient := func(addr string, isTLS bool) Transport {
+			d := dialPhases(dial)
+			if isTLS {
+				d = dialPhases(func(addr string) (net.Conn, error) {
+					return dialTLS(addr, tlsConfig, timeout)
+				})
+			}
+			hc := &fasthttp.HostClient{
+				Addr:                addr,
+				IsTLS:               isTLS,
+				Dial:                d,
+				MaxIdleConnDuration: *httpClientMaxIdleConnDuration,
+				MaxConnDuration:     *httpClientMaxConnDuration,
+				MaxConns:            maxConns,
+				ReadTimeout:         timeout,
+				WriteTimeout:        timeout,
+				RetryIfErr:          retryIfErr,
+			}
+			if *httpClientMaxConns > 0 {
+				hc.MaxConns = *httpClientMaxConns
+				hc.MaxConnWaitTimeout = timeout
+			}
+			return hc
+		}
+		c := NewWithTransport(request, newHostClient(addr, isTLS), sc)
+		c.closer = newConnCloser()
+		// hops are compared with url host, since addr may be a socket
+		origin, _ := uriAddr(request.URI())
+		c.redirecter = newRedirecter(origin, isTLS, newHostClient)
+		return c, nil
+	}
+	return NewWithTransport(request, t, sc), nil
+}
+
+// NewWithTransport creates new client, which sends requests via given transport.
+// Responses with status codes from sc list (e.g. 200-299,304) are considered as successful
+func NewWithTransport(request *fasthttp.Request, t Transport, sc string) *Client {
+	successStatusCodes, err := parseStatusRanges(sc)
+	if err != nil {
+		log.Fatalf("cannot parse success status codes: %s", err)
+	}
+	loginRequest, err := newLoginRequest(request)
+	if err != nil {
+		log.Fatalf("cannot init login request: %s", err)
+	}
+	flow, err := loadFlow(*flowFile, request)
+	if err != nil {
+		log.Fatalf("cannot load flow: %s", err)
+	}
+	script, err := loadScript(*scriptFile)
+	if err != nil {
+		log.Fatalf("cannot load script: %s", err)
+	}
+	auth, err := newAuthProvider()
+	if err != nil {
+		log.Fatalf("cannot init auth: %s", err)
+	}
 	flushMetrics()
-	addr, isTLS := acquireAddr(request)
 	return &Client{
-		Jobsch:            make(chan struct{}, jobCapacity),
-		request:           request,
-		statusCodeLabels:  make(map[int]prometheus.Labels),
-		errorMessages:     make(map[string]prometheus.Labels),
-		successStatusCode: sc,
-		HostClient: &fasthttp.HostClient{
-			Addr:                addr,
-			IsTLS:               isTLS,
-			Dial:                dial,
-			MaxIdleConnDuration: maxIdleConnDuration,
-			MaxConns:            maxConns,
-			ReadTimeout:         timeout,
-			WriteTimeout:        timeout,
-		},
+		loginRequest:       loginRequest,
+		flow:               flow,
+		script:             script,
+		auth:               auth,
+		Jobsch:             make(chan struct{}, jobCapacity),
+		transport:          t,
+		request:            request,
+		statusCodeLabels:   make(map[string]prometheus.Labels),
+		errorMessages:      make(map[string]prometheus.Labels),
+		successStatusCodes: successStatusCodes,
+		statusGroups:       make(map[string]string),
+	}
+}
+
+// Auth returns true if credentials are attached to requests
+func (c *Client) Auth() bool {
+	return c.auth != nil
+}
+
+// Script returns true if requests are processed by script
+func (c *Client) Script() bool {
+	return c.script != nil
+}
+
+// Sessions returns true if workers keep sessions
+func (c *Client) Sessions() bool {
+	return sessionsEnabled()
+}
+
+// FollowRedirects returns true if client follows redirects
+func (c *Client) FollowRedirects() bool {
+	return c.redirecter != nil
+}
+
+// HTTP2 returns true if client sends requests over HTTP/2
+func (c *Client) HTTP2() bool {
+	switch c.transport.(type) {
+	case *http2Client, *grpcTransport:
+		return true
 	}
+	return false
+}
+
+// EventStream returns true if client keeps event streams open
+func (c *Client) EventStream() bool {
+	_, ok := c.transport.(*eventStreamTransport)
+	return ok
+}
+
+// Websocket returns true if client sends messages over WebSocket
+func (c *Client) Websocket() bool {
+	_, ok := c.transport.(*wsTransport)
+	return ok
+}
+
+// TLS returns true if client establishes TLS connections
+func (c *Client) TLS() bool {
+	scheme := string(c.request.URI().Scheme())
+	return scheme == "https" || scheme == "wss"
 }
 
 // Amount return number of created workers
@@ -116,13 +280,14 @@ func (c *Client) RunWorkers(n int) {
 	if n < 1 {
 		n = 1
 	}
+	// workers are counted before start, so Amount
+	// reflects them as soon as RunWorkers returns
+	c.Lock()
+	c.workers += n
+	c.Unlock()
 	for i := 0; i < n; i++ {
 		c.wg.Add(1)
 		go func() {
-			c.Lock()
-			c.workers++
-			c.Unlock()
-
 			c.run()
 			c.wg.Done()
 		}()
@@ -133,9 +298,58 @@ func (c *Client) run() {
 	var resp fasthttp.Response
 	r := new(fasthttp.Request)
 	c.request.CopyTo(r)
+	var hop *fasthttp.Request
+	if c.redirecter != nil {
+		hop = new(fasthttp.Request)
+	}
+	t := c.transport
+	if wt, ok := t.(WorkerTransport); ok {
+		t = wt.Worker()
+		if closer, ok := t.(io.Closer); ok {
+			defer closer.Close()
+		}
+	}
+	if c.auth != nil {
+		t = &authTransport{t: t, p: c.auth}
+	}
+	if sessionsEnabled() {
+		t = newSession(t, c.request, c.loginRequest)
+	}
+	// redirects are followed without flow and script hooks
+	rt := t
+	if c.script != nil {
+		sw, err := newScriptWorker(c.script, t)
+		if err != nil {
+			t = &scriptFailure{err: err}
+		} else {
+			t = sw
+		}
+	}
+	// flow prepares request of every step by itself,
+	// otherwise changes made by script must not be accumulated
+	resetRequest := c.script != nil && c.flow == nil
+	if c.flow != nil {
+		t = newFlowWorker(c.flow, t, c.request)
+	}
 	for range c.Jobsch {
+		if resetRequest {
+			c.request.CopyTo(r)
+		}
+		closeConn := c.closer != nil && c.closer.close()
+		if closeConn {
+			r.Header.SetConnectionClose()
+		}
 		s := time.Now()
-		err := c.Do(r, &resp)
+		err := t.Do(r, &resp)
+		if c.redirecter != nil {
+			firstHopDuration.Observe(time.Since(s).Seconds())
+			if err == nil {
+				err = c.redirecter.follow(rt, r, &resp, hop)
+			}
+		}
+		if closeConn && !c.request.Header.ConnectionClose() {
+			r.Header.ResetConnectionClose()
+		}
 		if err != nil {
 			if err == fasthttp.ErrTimeout {
 				timeouts.Inc()
@@ -144,29 +358,93 @@ func (c *Client) run() {
 			c.withErrorMessage(err.Error()).Inc()
 		}
 
-		sc := resp.StatusCode()
-		if c.successStatusCode == sc {
+		sc, label := c.status(&resp)
+		if err != nil {
+			// response could contain default status code after error
+			sc, label = 0, statusError
+		}
+		success := err == nil && c.successStatusCodes.contains(sc)
+		// assertions are checked for every response, so failures
+		// are counted even if status code isn't successful
+		if err == nil && len(assertions) > 0 && !assertions.check(&resp) {
+			success = false
+		}
+		if success {
 			requestSuccess.Inc()
 		}
 
-		c.withStatusCode(sc).Inc()
-		requestDuration.Observe(float64(time.Since(s).Seconds()))
+		c.withStatusCode(sc, label).Inc()
+		d := time.Since(s).Seconds()
+		requestDuration.Observe(d)
+		recentRequestDuration.Observe(d)
 		requestSum.Inc()
 	}
 }
 
-func (c *Client) withStatusCode(code int) prometheus.Counter {
+// status returns status code of response and its label.
+// Transport may override HTTP status codes by implementing Statuser
+func (c *Client) status(resp *fasthttp.Response) (int, string) {
+	if s, ok := c.transport.(Statuser); ok {
+		return s.Status(resp)
+	}
+	sc := resp.StatusCode()
+	return sc, strconv.Itoa(sc)
+}
+
+func (c *Client) withStatusCode(sc int, code string) prometheus.Counter {
 	var label prometheus.Labels
 	var ok bool
 	c.Lock()
 	if label, ok = c.statusCodeLabels[code]; !ok {
-		label = prometheus.Labels{"code": strconv.Itoa(code)}
+		label = prometheus.Labels{"code": code}
 		c.statusCodeLabels[code] = label
+		c.statusGroups[code] = c.statusGroup(sc, code)
 	}
 	c.Unlock()
 	return statusCodes.With(label)
 }
 
+// Groups of status codes
+const (
+	statusSuccess     = "success"
+	statusClientError = "client error"
+	statusServerError = "server error"
+	statusError       = "error"
+	statusOther       = "other"
+)
+
+// statusGroup returns group of status code.
+// Transports with own status codes (see Statuser) have
+// only success and other groups
+func (c *Client) statusGroup(sc int, label string) string {
+	_, custom := c.transport.(Statuser)
+	switch {
+	case label == statusError:
+		return statusError
+	case c.successStatusCodes.contains(sc):
+		return statusSuccess
+	case custom:
+		return statusOther
+	case sc >= 400 && sc < 500:
+		return statusClientError
+	case sc >= 500 && sc < 600:
+		return statusServerError
+	}
+	return statusOther
+}
+
+// StatusGroups returns map statusCode:group for status codes
+// of sent requests
+func (c *Client) StatusGroups() map[string]string {
+	c.Lock()
+	defer c.Unlock()
+	result := make(map[string]string, len(c.statusGroups))
+	for k, v := range c.statusGroups {
+		result[k] = v
+	}
+	return result
+}
+
 func (c *Client) withErrorMessage(msg string) prometheus.Counter {
 	var label prometheus.Labels
 	var ok bool
@@ -188,29 +466,116 @@ type hostConn struct {
 	writeError   prometheus.Counter
 	bytesWritten prometheus.Counter
 	bytesRead    prometheus.Counter
+
+	ipBytesWritten prometheus.Counter
+	ipBytesRead    prometheus.Counter
+
+	// phases enables measuring of TTFB and body transfer
+	// and counting of requests, which is possible
+	// only if requests are sent one by one
+	phases    bool
+	requests  int
+	writing   bool
+	writeTime time.Time
+	firstByte time.Time
+	lastRead  time.Time
 }
 
 func dial(addr string) (net.Conn, error) {
-	conn, err := fasthttp.DialTimeout(addr, *httpClientRequestTimeout)
+	deadline := time.Now().Add(*httpClientRequestTimeout)
+	var conn net.Conn
+	var ip string
+	var err error
+	if proxyURL != nil {
+		conn, ip, err = dialProxy(addr, deadline)
+	} else {
+		conn, ip, err = dialDirect(addr, deadline)
+	}
 	if err != nil {
 		return nil, err
 	}
+
+	connOpen.Inc()
+	newConns.Inc()
+	label := prometheus.Labels{"ip": ip}
+	ipConnections.With(label).Inc()
+	return &hostConn{
+		Conn:           conn,
+		addr:           addr,
+		connOpen:       connOpen,
+		readError:      readError,
+		writeError:     writeError,
+		bytesWritten:   bytesWritten,
+		bytesRead:      bytesRead,
+		ipBytesWritten: ipBytesWritten.With(label),
+		ipBytesRead:    ipBytesRead.With(label),
+	}, nil
+}
+
+// dialDirect establishes connection to addr
+// and returns it along with IP or socket it is connected to
+func dialDirect(addr string, deadline time.Time) (net.Conn, string, error) {
+	d := net.Dialer{
+		Deadline: deadline,
+	}
+	network, target, ip := "unix", *unixSocket, ""
+	if strings.HasPrefix(addr, unixAddrPrefix) {
+		target = addr[len(unixAddrPrefix):]
+	}
+	if target != "" {
+		ip = unixAddrPrefix + target
+	} else {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, "", err
+		}
+		if ip, err = resolve(host, port, deadline); err != nil {
+			return nil, "", err
+		}
+		network, target = "tcp", net.JoinHostPort(ip, port)
+		if d.LocalAddr, err = localAddrs.get(ip); err != nil {
+			return nil, "", err
+		}
+	}
+
+	s := time.Now()
+	conn, err := d.Dial(network, target)
+	if err != nil {
+		if ne, ok := err.(net.Error); ok && ne.Timeout() {
+			return nil, "", fasthttp.ErrDialTimeout
+		}
+		if isAddrNotAvail(err) {
+			portsExhausted.Inc()
+			return nil, "", errPortsExhausted
+		}
+		return nil, "", err
+	}
+	connectDuration.Observe(time.Since(s).Seconds())
 	if err = setupTCPConn(conn); err != nil {
 		connError.Inc()
 		conn.Close()
-		return nil, err
+		return nil, "", err
 	}
+	return conn, ip, nil
+}
 
-	connOpen.Inc()
-	return &hostConn{
-		Conn:         conn,
-		addr:         addr,
-		connOpen:     connOpen,
-		readError:    readError,
-		writeError:   writeError,
-		bytesWritten: bytesWritten,
-		bytesRead:    bytesRead,
-	}, nil
+// dialPhases returns dial func, which establishes
+// connections measuring TTFB and body transfer
+func dialPhases(dial fasthttp.DialFunc) fasthttp.DialFunc {
+	return func(addr string) (net.Conn, error) {
+		conn, err := dial(addr)
+		if err != nil {
+			return nil, err
+		}
+		c := conn
+		if tc, ok := c.(*tls.Conn); ok {
+			c = tc.NetConn()
+		}
+		if hc, ok := c.(*hostConn); ok {
+			hc.phases = true
+		}
+		return conn, nil
+	}
 }
 
 func setupTCPConn(conn net.Conn) error {
@@ -244,14 +609,33 @@ func setupTCPConn(conn net.Conn) error {
 func (hc *hostConn) Close() error {
 	if atomic.AddUint32(&hc.closed, 1) == 1 {
 		hc.connOpen.Dec()
+		if hc.phases && !hc.firstByte.IsZero() {
+			bodyDuration.Observe(hc.lastRead.Sub(hc.firstByte).Seconds())
+		}
 	}
 
 	return hc.Conn.Close()
 }
 
 func (hc *hostConn) Write(p []byte) (int, error) {
+	if hc.phases && !hc.writing {
+		// request isn't sent, so it could be retried over new connection
+		if n := *httpClientMaxRequestsPerConn; n > 0 && hc.requests >= n {
+			hc.Close()
+			return 0, errConnExpired
+		}
+		hc.requests++
+		// previous response was read completely
+		if !hc.firstByte.IsZero() {
+			bodyDuration.Observe(hc.lastRead.Sub(hc.firstByte).Seconds())
+			hc.firstByte = time.Time{}
+		}
+		hc.writing = true
+		hc.writeTime = time.Now()
+	}
 	n, err := hc.Conn.Write(p)
 	hc.bytesWritten.Add(float64(n))
+	hc.ipBytesWritten.Add(float64(n))
 	if err != nil {
 		hc.writeError.Inc()
 	}
@@ -261,34 +645,79 @@ func (hc *hostConn) Write(p []byte) (int, error) {
 func (hc *hostConn) Read(p []byte) (int, error) {
 	n, err := hc.Conn.Read(p)
 	hc.bytesRead.Add(float64(n))
+	hc.ipBytesRead.Add(float64(n))
 	if err != nil && err != io.EOF {
 		hc.readError.Inc()
 	}
+	if hc.phases && n > 0 {
+		hc.lastRead = time.Now()
+		if hc.writing {
+			hc.writing = false
+			hc.firstByte = hc.lastRead
+			ttfbDuration.Observe(hc.firstByte.Sub(hc.writeTime).Seconds())
+		}
+	}
 	return n, err
 }
 
-func acquireAddr(req *fasthttp.Request) (string, bool) {
+// acquireAddr returns address to connect to and whether connection is TLS.
+// Address is unix:/path/to/sock if -unixSocket flag is set or url is
+// unix:/path/to/sock[:/request/uri]. In the latter case req is rewritten
+// to http://host/request/uri, where host is taken from Host header
+// or is localhost if header isn't set
+func acquireAddr(req *fasthttp.Request) (string, bool, error) {
+	if uri := string(req.Header.RequestURI()); strings.HasPrefix(uri, unixAddrPrefix) {
+		return acquireUnixAddr(req, uri[len(unixAddrPrefix):])
+	}
+	scheme := string(req.URI().Scheme())
+	isTLS := scheme == "https" || scheme == "wss"
+	if *unixSocket != "" {
+		return unixAddrPrefix + *unixSocket, isTLS, nil
+	}
 	addr := string(req.URI().Host())
 	if len(addr) == 0 {
-		log.Fatalf("address cannot be empty")
+		return "", false, fmt.Errorf("address cannot be empty")
 	}
-	isTLS := string(req.URI().Scheme()) == "https"
 	tmp := strings.SplitN(addr, ":", 2)
 	if len(tmp) != 2 {
 		port := ":80"
 		if isTLS {
 			port = ":443"
 		}
-		return tmp[0] + port, isTLS
+		return tmp[0] + port, isTLS, nil
 	}
 	port := tmp[1]
 	portInt, err := strconv.Atoi(port)
 	if err != nil {
-		log.Fatalf("cannot parse port %q of addr %q: %s", port, addr, err)
+		return "", false, fmt.Errorf("cannot parse port %q of addr %q: %s", port, addr, err)
 	}
 	if portInt < 0 {
-		log.Fatalf("upstreamHosts port %d cannot be negative: %q", portInt, addr)
+		return "", false, fmt.Errorf("port %d cannot be negative: %q", portInt, addr)
 	}
 
-	return addr, isTLS
+	return addr, isTLS, nil
+}
+
+// acquireUnixAddr rewrites req with unix:path url to plain http url
+// and returns socket address from path, which may contain query string
+func acquireUnixAddr(req *fasthttp.Request, path string) (string, bool, error) {
+	if *unixSocket != "" {
+		return "", false, fmt.Errorf("-unixSocket can't be used along with unix: url")
+	}
+	sock, uri := path, "/"
+	if n := strings.Index(path, ":"); n >= 0 {
+		sock, uri = path[:n], path[n+1:]
+	}
+	if sock == "" {
+		return "", false, fmt.Errorf("socket path cannot be empty")
+	}
+	if !strings.HasPrefix(uri, "/") {
+		return "", false, fmt.Errorf("request uri %q must start with /", uri)
+	}
+	host := string(req.Header.Host())
+	if host == "" {
+		host = "localhost"
+	}
+	req.SetRequestURI("http://" + host + uri)
+	return unixAddrPrefix + sock, false, nil
 }
@@ -0,0 +1,93 @@
+package fastclient
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// mockTransport responds with status codes from list by turn.
+// Zero code means error along with 502 status code
+type mockTransport struct {
+	codes []int
+	n     uint32
+}
+
+func (mt *mockTransport) Do(req *fasthttp.Request, resp *fasthttp.Response) error {
+	resp.Reset()
+	code := mt.codes[int(atomic.AddUint32(&mt.n, 1)-1)%len(mt.codes)]
+	if code == 0 {
+		resp.SetStatusCode(fasthttp.StatusBadGateway)
+		return fmt.Errorf("mock error")
+	}
+	resp.SetStatusCode(code)
+	return nil
+}
+
+func TestClientTransport(t *testing.T) {
+	req := new(fasthttp.Request)
+	req.SetRequestURI("http://localhost/")
+	c := NewWithTransport(req, &mockTransport{codes: []int{200, 200, 500, 0}}, "200")
+	c.RunWorkers(4)
+	// workers must be stopped before the next test changes flags
+	defer c.Flush()
+	for i := 0; i < 100; i++ {
+		c.Jobsch <- struct{}{}
+	}
+	waitRequests(t, c, 100)
+
+	if c.RequestSuccess() != 50 {
+		t.Errorf("Unexpected number of successful requests. Got: %d; Expected: %d", c.RequestSuccess(), 50)
+	}
+	if c.Errors() != 25 {
+		t.Errorf("Unexpected number of errors. Got: %d; Expected: %d", c.Errors(), 25)
+	}
+	if n := c.ErrorMessages()["mock error"]; n != 25 {
+		t.Errorf("Unexpected number of error messages. Got: %d; Expected: %d", n, 25)
+	}
+	if p := c.StatusCodes()["500"]; p != 25 {
+		t.Errorf("Unexpected percent of status code 500. Got: %.2f; Expected: %d", p, 25)
+	}
+}
+
+func TestClientStatusGroups(t *testing.T) {
+	req := new(fasthttp.Request)
+	req.SetRequestURI("http://localhost/")
+	c := NewWithTransport(req, &mockTransport{codes: []int{201, 304, 404, 503, 0}}, "200-299,304")
+	c.RunWorkers(1)
+	defer c.Flush()
+	for i := 0; i < 10; i++ {
+		c.Jobsch <- struct{}{}
+	}
+	waitRequests(t, c, 10)
+
+	if c.RequestSuccess() != 4 {
+		t.Errorf("Unexpected number of successful requests. Got: %d; Expected: %d", c.RequestSuccess(), 4)
+	}
+	expected := map[string]string{
+		"201":   statusSuccess,
+		"304":   statusSuccess,
+		"404":   statusClientError,
+		"503":   statusServerError,
+		"error": statusError,
+	}
+	groups := c.StatusGroups()
+	for code, group := range expected {
+		if groups[code] != group {
+			t.Errorf("Unexpected group of %q. Got: %q; Expected: %q", code, groups[code], group)
+		}
+	}
+}
+
+func waitRequests(t *testing.T, c *Client, n uint64) {
+	deadline := time.Now().Add(5 * time.Second)
+	for c.RequestSum() < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timeout while waiting for requests. Got: %d; Expected: %d", c.RequestSum(), n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,193 @@
+package fastclient
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+var (
+	eventStream = flag.String("eventStream", "", "Keep streaming connections open instead of sending requests. "+
+		"Could be sse for Server-Sent Events or longpoll for long polling")
+	eventStreamLifetime = flag.Duration("eventStreamLifetime", 30*time.Second, "Max lifetime of sse connection or max duration of long poll. "+
+		"Stream closed by client after lifetime is not counted as drop, while stream closed by server or broken before lifetime is")
+)
+
+const (
+	eventStreamSSE      = "sse"
+	eventStreamLongPoll = "longpoll"
+)
+
+// isEventStream returns true if connections should be kept open
+func isEventStream() bool {
+	return *eventStream != ""
+}
+
+// eventStreamTransport reads events from streaming responses.
+// Do blocks until stream ends, so every worker keeps one stream open
+type eventStreamTransport struct {
+	mode   string
+	client *http.Client
+}
+
+func newEventStreamTransport(timeout time.Duration, tlsConfig *tls.Config) (*eventStreamTransport, error) {
+	if *eventStream != eventStreamSSE && *eventStream != eventStreamLongPoll {
+		return nil, fmt.Errorf("unsupported eventStream %q; supported values are %q and %q", *eventStream, eventStreamSSE, eventStreamLongPoll)
+	}
+	return &eventStreamTransport{
+		mode: *eventStream,
+		client: &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+					return dial(addr)
+				},
+				DialTLSContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+					return dialTLS(addr, tlsConfig, timeout)
+				},
+				DisableCompression: true,
+			},
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}, nil
+}
+
+// Worker returns Transport which measures gaps between events of one worker
+func (et *eventStreamTransport) Worker() Transport {
+	return &eventStreamWorker{eventStreamTransport: et}
+}
+
+// Do reads single stream
+func (et *eventStreamTransport) Do(req *fasthttp.Request, resp *fasthttp.Response) error {
+	return et.Worker().Do(req, resp)
+}
+
+type eventStreamWorker struct {
+	*eventStreamTransport
+	lastEvent time.Time
+}
+
+// Do opens stream and reads events until it ends.
+// Stream which ended before eventStreamLifetime is counted as drop
+func (w *eventStreamWorker) Do(req *fasthttp.Request, resp *fasthttp.Response) error {
+	resp.Reset()
+	ctx, cancel := context.WithTimeout(context.Background(), *eventStreamLifetime)
+	defer cancel()
+
+	s := time.Now()
+	trace := &httptrace.ClientTrace{
+		GotFirstResponseByte: func() {
+			eventStreamTTFB.Observe(time.Since(s).Seconds())
+		},
+	}
+	hr, err := acquireHTTPRequest(httptrace.WithClientTrace(ctx, trace), req)
+	if err != nil {
+		return err
+	}
+	if w.mode == eventStreamSSE {
+		hr.Header.Set("Accept", "text/event-stream")
+	}
+
+	res, err := w.client.Do(hr)
+	if err != nil {
+		return w.drop(ctx, err)
+	}
+	defer res.Body.Close()
+	resp.SetStatusCode(res.StatusCode)
+	// gap is measured from the start of every stream,
+	// so time spent on reconnect isn't counted.
+	// Long poll gap includes waiting for response
+	if w.mode == eventStreamLongPoll {
+		w.lastEvent = s
+	} else {
+		w.lastEvent = time.Now()
+	}
+
+	if w.mode == eventStreamLongPoll {
+		_, err = io.Copy(ioutil.Discard, res.Body)
+		if err == nil {
+			w.event()
+		}
+	} else {
+		err = w.readSSE(res.Body)
+	}
+	eventStreamDuration.Observe(time.Since(s).Seconds())
+	if err != nil {
+		return w.drop(ctx, err)
+	}
+	return nil
+}
+
+// readSSE reads events until stream ends.
+// io.ErrUnexpectedEOF is returned if stream is closed by server,
+// so it is counted as drop. Event is dispatched on empty line if it contains data,
+// according to https://html.spec.whatwg.org/multipage/server-sent-events.html
+func (w *eventStreamWorker) readSSE(r io.Reader) error {
+	br := bufio.NewReader(r)
+	pending := false
+	// tail is true while reading the rest of line longer than buffer
+	tail := false
+	for {
+		line, err := br.ReadSlice('\n')
+		if err == io.EOF {
+			// incomplete event is discarded
+			return io.ErrUnexpectedEOF
+		}
+		if err != nil && err != bufio.ErrBufferFull {
+			return err
+		}
+		if !tail {
+			switch {
+			case len(bytes.TrimRight(line, "\r\n")) == 0:
+				if pending {
+					w.event()
+					pending = false
+				}
+			case isSSEData(line):
+				pending = true
+			}
+		}
+		tail = err == bufio.ErrBufferFull
+	}
+}
+
+// isSSEData returns true if line is a data field.
+// Field without colon has empty value
+func isSSEData(line []byte) bool {
+	field := bytes.TrimRight(line, "\r\n")
+	if n := bytes.IndexByte(field, ':'); n >= 0 {
+		field = field[:n]
+	}
+	return string(field) == "data"
+}
+
+func (w *eventStreamWorker) event() {
+	now := time.Now()
+	eventGap.Observe(now.Sub(w.lastEvent).Seconds())
+	events.Inc()
+	w.lastEvent = now
+}
+
+// drop counts stream as dropped, unless it was closed due to lifetime
+func (w *eventStreamWorker) drop(ctx context.Context, err error) error {
+	if ctx.Err() == context.DeadlineExceeded {
+		if w.mode == eventStreamLongPoll {
+			return fasthttp.ErrTimeout
+		}
+		return nil
+	}
+	eventStreamDrops.Inc()
+	return err
+}
@@ -0,0 +1,139 @@
+package fastclient
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestReadSSE(t *testing.T) {
+	testCases := []struct {
+		name     string
+		stream   string
+		expected uint64
+	}{
+		{
+			name:     "single line data",
+			stream:   "data: a\n\ndata: b\n\n",
+			expected: 2,
+		},
+		{
+			name:     "multi-line data",
+			stream:   "data: a\ndata: b\ndata: c\n\n",
+			expected: 1,
+		},
+		{
+			name:     "crlf",
+			stream:   "data: a\r\n\r\ndata: b\r\n\r\n",
+			expected: 2,
+		},
+		{
+			name:     "comments",
+			stream:   ": ping\n\n:\n\ndata: a\n: inside event\n\n",
+			expected: 1,
+		},
+		{
+			name:     "id, retry and event without data",
+			stream:   "id: 1\n\nretry: 1000\n\nevent: update\n\n",
+			expected: 0,
+		},
+		{
+			name:     "id, retry and event with data",
+			stream:   "id: 1\nretry: 1000\nevent: update\ndata: a\n\n",
+			expected: 1,
+		},
+		{
+			name:     "field without colon",
+			stream:   "data\n\n",
+			expected: 1,
+		},
+		{
+			name:     "field with data prefix",
+			stream:   "database: a\n\n",
+			expected: 0,
+		},
+		{
+			name:     "long line",
+			stream:   "data: " + strings.Repeat("a", 10000) + "\n\n: " + strings.Repeat("b", 10000) + "\n\n",
+			expected: 1,
+		},
+		{
+			name:     "incomplete event is discarded",
+			stream:   "data: a\n\ndata: b\n",
+			expected: 1,
+		},
+	}
+	for _, tc := range testCases {
+		w := &eventStreamWorker{lastEvent: time.Now()}
+		before := (*Client)(nil).Events()
+		// reader ends before lifetime, so stream is closed by server
+		if err := w.readSSE(strings.NewReader(tc.stream)); err != io.ErrUnexpectedEOF {
+			t.Errorf("%s: unexpected error. Got: %v; Expected: %v", tc.name, err, io.ErrUnexpectedEOF)
+		}
+		if n := (*Client)(nil).Events() - before; n != tc.expected {
+			t.Errorf("%s: unexpected number of events. Got: %d; Expected: %d", tc.name, n, tc.expected)
+		}
+	}
+}
+
+func TestEventStreamReconnect(t *testing.T) {
+	*eventStream = eventStreamSSE
+	defer func() { *eventStream = "" }()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != "text/event-stream" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintf(w, "data: a\n\ndata: b\n\n")
+		if r.URL.Path == "/hold" {
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}
+	}))
+	defer srv.Close()
+
+	et, err := newEventStreamTransport(time.Second, &tls.Config{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	w := et.Worker()
+	req := new(fasthttp.Request)
+	req.SetRequestURI(srv.URL)
+	resp := new(fasthttp.Response)
+	events, drops := (*Client)(nil).Events(), (*Client)(nil).EventStreamDrops()
+	for i := 0; i < 2; i++ {
+		// stream closed by server before lifetime is dropped
+		if err := w.Do(req, resp); err != io.ErrUnexpectedEOF {
+			t.Fatalf("Unexpected error. Got: %v; Expected: %v", err, io.ErrUnexpectedEOF)
+		}
+		if resp.StatusCode() != fasthttp.StatusOK {
+			t.Fatalf("Unexpected status code. Got: %d; Expected: %d", resp.StatusCode(), fasthttp.StatusOK)
+		}
+	}
+	if n := (*Client)(nil).Events() - events; n != 4 {
+		t.Errorf("Unexpected number of events. Got: %d; Expected: %d", n, 4)
+	}
+	if n := (*Client)(nil).EventStreamDrops() - drops; n != 2 {
+		t.Errorf("Unexpected number of drops. Got: %d; Expected: %d", n, 2)
+	}
+
+	// stream closed by client after lifetime isn't dropped
+	*eventStreamLifetime = 200 * time.Millisecond
+	defer func() { *eventStreamLifetime = 30 * time.Second }()
+	drops = (*Client)(nil).EventStreamDrops()
+	req.SetRequestURI(srv.URL + "/hold")
+	if err := w.Do(req, resp); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if n := (*Client)(nil).EventStreamDrops() - drops; n != 0 {
+		t.Errorf("Unexpected number of drops. Got: %d; Expected: %d", n, 0)
+	}
+}
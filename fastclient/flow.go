@@ -0,0 +1,344 @@
+package fastclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/valyala/fasthttp"
+)
+
+var flowFile = flag.String("flow", "", "JSON file with user flow. Every worker acts as virtual user and sends requests of flow steps by turn, "+
+	"using values extracted from previous responses. Url, headers and body of tested request are used as defaults for steps")
+
+// flow is a sequence of requests sent by every virtual user
+type flow struct {
+	Steps []*flowStep `json:"steps"`
+}
+
+// flowStep is a single request of flow.
+// Url, headers and body may contain templates like {{.token}}
+// with values extracted from previous responses
+type flowStep struct {
+	Name    string            `json:"name"`
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+	Body    string            `json:"body"`
+
+	// Status is a list of status codes and ranges of successful step.
+	// Any status code below 400 is successful if not set
+	Status string `json:"status"`
+
+	// Extract maps names of values to extractors like
+	// json:data.token, header:Location or regex:id=(\d+)
+	Extract map[string]string `json:"extract"`
+
+	url        *template.Template
+	body       *template.Template
+	headers    map[string]*template.Template
+	status     statusRanges
+	extractors map[string]extractor
+	labels     prometheus.Labels
+}
+
+// extractor returns value from response
+type extractor func(r *assertedResponse) (string, bool)
+
+// loadFlow reads flow from file
+// or returns nil if path is empty.
+// Steps are sent over connections to host of base request,
+// so urls of other hosts are rejected
+func loadFlow(path string, base *fasthttp.Request) (*flow, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f flow
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("cannot parse flow %q: %s", path, err)
+	}
+	if len(f.Steps) == 0 {
+		return nil, fmt.Errorf("flow %q contains no steps", path)
+	}
+	for i, s := range f.Steps {
+		if s.Name == "" {
+			s.Name = fmt.Sprintf("step %d", i+1)
+		}
+		if err := s.compile(); err != nil {
+			return nil, fmt.Errorf("cannot parse step %q: %s", s.Name, err)
+		}
+		// urls with templates are checked when rendered
+		if !strings.Contains(s.URL, "{{") {
+			if err := checkStepHost(base, []byte(s.URL)); err != nil {
+				return nil, fmt.Errorf("cannot parse step %q: %s", s.Name, err)
+			}
+		}
+	}
+	return &f, nil
+}
+
+// checkStepHost returns error if url points to host or scheme
+// other than of base request
+func checkStepHost(base *fasthttp.Request, url []byte) error {
+	u := fasthttp.AcquireURI()
+	defer fasthttp.ReleaseURI(u)
+	base.URI().CopyTo(u)
+	u.UpdateBytes(url)
+	if !bytes.Equal(u.Host(), base.URI().Host()) || !bytes.Equal(u.Scheme(), base.URI().Scheme()) {
+		return fmt.Errorf("url %q points to host other than %q", url, base.URI().Host())
+	}
+	return nil
+}
+
+func (s *flowStep) compile() error {
+	var err error
+	parse := func(name, text string) *template.Template {
+		if err != nil {
+			return nil
+		}
+		var t *template.Template
+		t, err = template.New(name).Option("missingkey=error").Parse(text)
+		return t
+	}
+	s.url = parse("url", s.URL)
+	s.body = parse("body", s.Body)
+	s.headers = make(map[string]*template.Template, len(s.Headers))
+	for k, v := range s.Headers {
+		s.headers[k] = parse(k, v)
+	}
+	if err != nil {
+		return err
+	}
+
+	status := s.Status
+	if status == "" {
+		status = "0-399"
+	}
+	if s.status, err = parseStatusRanges(status); err != nil {
+		return err
+	}
+
+	s.extractors = make(map[string]extractor, len(s.Extract))
+	for name, v := range s.Extract {
+		if s.extractors[name], err = parseExtractor(v); err != nil {
+			return err
+		}
+	}
+	s.labels = prometheus.Labels{"step": s.Name}
+	return nil
+}
+
+func parseExtractor(value string) (extractor, error) {
+	n := strings.Index(value, ":")
+	if n < 0 {
+		return nil, fmt.Errorf("cannot parse extractor %q; expected format is kind:argument", value)
+	}
+	kind, arg := value[:n], value[n+1:]
+	switch kind {
+	case "json":
+		path := splitJSONPath(arg)
+		return func(r *assertedResponse) (string, bool) {
+			doc, ok := r.getJSON()
+			if !ok {
+				return "", false
+			}
+			v, ok := lookupJSONPath(doc, path)
+			if !ok {
+				return "", false
+			}
+			switch v := v.(type) {
+			case string:
+				return v, true
+			case float64:
+				return strconv.FormatFloat(v, 'f', -1, 64), true
+			}
+			b, err := json.Marshal(v)
+			return string(b), err == nil
+		}, nil
+	case "header":
+		return func(r *assertedResponse) (string, bool) {
+			v := r.resp.Header.Peek(arg)
+			return string(v), v != nil
+		}, nil
+	case "regex":
+		re, err := regexp.Compile(arg)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse regex extractor %q: %s", value, err)
+		}
+		return func(r *assertedResponse) (string, bool) {
+			m := re.FindSubmatch(r.getBody())
+			if m == nil {
+				return "", false
+			}
+			// the first group is returned if regex contains groups
+			return string(m[len(m)-1]), true
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported extractor %q; supported kinds are json, header and regex", kind)
+}
+
+// flowWorker is a Transport of virtual user, which turns
+// every request into the next step of flow.
+// Flow is started over if any step fails
+type flowWorker struct {
+	*flow
+	t Transport
+
+	// base is a tested request used as default for steps
+	base   *fasthttp.Request
+	step   int
+	values map[string]string
+	buf    bytes.Buffer
+}
+
+func newFlowWorker(f *flow, t Transport, base *fasthttp.Request) *flowWorker {
+	return &flowWorker{
+		flow:   f,
+		t:      t,
+		base:   base,
+		values: make(map[string]string),
+	}
+}
+
+// Do sends request of the next step
+func (fw *flowWorker) Do(req *fasthttp.Request, resp *fasthttp.Response) error {
+	s := fw.Steps[fw.step]
+	flowStepRequests.With(s.labels).Inc()
+	if err := fw.prepare(s, req); err != nil {
+		resp.Reset()
+		return fw.fail(s, fmt.Errorf("cannot prepare step %q: %s", s.Name, err))
+	}
+
+	start := time.Now()
+	err := fw.t.Do(req, resp)
+	flowStepDuration.With(s.labels).Observe(time.Since(start).Seconds())
+	if err != nil {
+		return fw.fail(s, err)
+	}
+	if !s.status.contains(resp.StatusCode()) {
+		// status code is counted by client, so error isn't returned
+		fw.fail(s, nil)
+		return nil
+	}
+
+	r := &assertedResponse{resp: resp}
+	for name, e := range s.extractors {
+		v, ok := e(r)
+		if !ok {
+			return fw.fail(s, fmt.Errorf("cannot extract %q at step %q", name, s.Name))
+		}
+		fw.values[name] = v
+	}
+
+	fw.step = (fw.step + 1) % len(fw.Steps)
+	if fw.step == 0 {
+		fw.reset()
+	}
+	return nil
+}
+
+// prepare turns req into request of step s
+func (fw *flowWorker) prepare(s *flowStep, req *fasthttp.Request) error {
+	connClose := req.Header.ConnectionClose()
+	fw.base.CopyTo(req)
+	if connClose {
+		req.Header.SetConnectionClose()
+	}
+
+	if s.Method != "" {
+		req.Header.SetMethod(s.Method)
+	}
+	if s.URL != "" {
+		if err := fw.render(s.url); err != nil {
+			return err
+		}
+		if err := checkStepHost(fw.base, fw.buf.Bytes()); err != nil {
+			return err
+		}
+		req.URI().UpdateBytes(fw.buf.Bytes())
+	}
+	for k, t := range s.headers {
+		if err := fw.render(t); err != nil {
+			return err
+		}
+		req.Header.SetBytesV(k, fw.buf.Bytes())
+	}
+	if s.Body != "" {
+		if err := fw.render(s.body); err != nil {
+			return err
+		}
+		req.SetBody(fw.buf.Bytes())
+	}
+	return nil
+}
+
+func (fw *flowWorker) render(t *template.Template) error {
+	fw.buf.Reset()
+	return t.Execute(&fw.buf, fw.values)
+}
+
+// fail counts failure of step and starts flow over
+func (fw *flowWorker) fail(s *flowStep, err error) error {
+	flowStepFailures.With(s.labels).Inc()
+	fw.step = 0
+	fw.reset()
+	return err
+}
+
+func (fw *flowWorker) reset() {
+	for k := range fw.values {
+		delete(fw.values, k)
+	}
+}
+
+// FlowStep contains stats of flow step
+type FlowStep struct {
+	Name     string
+	Requests uint64
+	Failures uint64
+
+	// Duration contains latency quantiles,
+	// which are zero if step wasn't executed
+	Duration map[float64]float64
+}
+
+// FlowSteps returns stats of flow steps in order of their execution
+// or nil if flow isn't set
+func (c *Client) FlowSteps() []FlowStep {
+	if c.flow == nil {
+		return nil
+	}
+	requests := countersByLabel(flowStepRequests)
+	failures := countersByLabel(flowStepFailures)
+	var result []FlowStep
+	for _, s := range c.flow.Steps {
+		fs := FlowStep{
+			Name:     s.Name,
+			Requests: requests[s.Name],
+			Failures: failures[s.Name],
+		}
+		if o, err := flowStepDuration.GetMetricWith(s.labels); err == nil {
+			fs.Duration = quantiles(o.(prometheus.Summary))
+			for q, v := range fs.Duration {
+				if math.IsNaN(v) {
+					fs.Duration[q] = 0
+				}
+			}
+		}
+		result = append(result, fs)
+	}
+	return result
+}
@@ -0,0 +1,113 @@
+package fastclient
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+// flowTransport serves login, items list and single item
+// and records uris with authorization headers of requests
+type flowTransport struct {
+	requests []string
+}
+
+func (ft *flowTransport) Do(req *fasthttp.Request, resp *fasthttp.Response) error {
+	resp.Reset()
+	ft.requests = append(ft.requests, string(req.Header.Method())+" "+string(req.URI().RequestURI())+" "+string(req.Header.Peek("Authorization")))
+	switch string(req.URI().Path()) {
+	case "/login":
+		resp.Header.Set("X-Session", "s1")
+		resp.SetBodyString(`{"data":{"token":"abc"}}`)
+	case "/items":
+		if string(req.Header.Peek("Authorization")) != "Bearer abc" {
+			resp.SetStatusCode(fasthttp.StatusUnauthorized)
+			return nil
+		}
+		resp.SetBodyString(`{"items":[{"id":42},{"id":43}]}`)
+	default:
+		resp.SetBodyString(`item`)
+	}
+	return nil
+}
+
+const testFlow = `{"steps": [
+	{"name": "login", "method": "POST", "url": "/login", "body": "user=test",
+		"extract": {"token": "json:data.token", "session": "header:X-Session"}},
+	{"name": "list", "url": "/items", "headers": {"Authorization": "Bearer {{.token}}"},
+		"extract": {"id": "regex:\"id\":(\\d+)"}},
+	{"url": "/items/{{.id}}?s={{.session}}"}
+]}`
+
+func TestFlow(t *testing.T) {
+	f, err := ioutil.TempFile("", "flow")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	defer os.Remove(f.Name())
+	f.WriteString(testFlow)
+	f.Close()
+
+	base := new(fasthttp.Request)
+	base.SetRequestURI("http://localhost/")
+	fl, err := loadFlow(f.Name(), base)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if name := fl.Steps[2].Name; name != "step 3" {
+		t.Fatalf("Unexpected default step name. Got: %q; Expected: %q", name, "step 3")
+	}
+
+	ft := &flowTransport{}
+	fw := newFlowWorker(fl, ft, base)
+	req := new(fasthttp.Request)
+	resp := new(fasthttp.Response)
+	for i := 0; i < 4; i++ {
+		if err := fw.Do(req, resp); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	}
+	expected := []string{"POST /login ", "GET /items Bearer abc", "GET /items/42?s=s1 ", "POST /login "}
+	for i, r := range ft.requests {
+		if r != expected[i] {
+			t.Errorf("Unexpected request %d. Got: %q; Expected: %q", i, r, expected[i])
+		}
+	}
+
+	// missing value fails step and starts flow over
+	fw.step = 2
+	fw.reset()
+	if err := fw.Do(req, resp); err == nil {
+		t.Errorf("Expected error for missing value")
+	}
+	if fw.step != 0 {
+		t.Errorf("Expected flow to start over. Got step: %d", fw.step)
+	}
+
+	// steps of other hosts would be sent over connection to tested host
+	fw = newFlowWorker(&flow{Steps: []*flowStep{{Name: "other", URL: "http://{{.host}}/"}}}, ft, base)
+	fw.Steps[0].compile()
+	fw.values["host"] = "example.com"
+	if err := fw.Do(req, resp); err == nil || !strings.Contains(err.Error(), "host other than") {
+		t.Errorf("Unexpected error for step of other host. Got: %v; Expected: %q", err, "host other than")
+	}
+
+	for _, s := range []string{
+		`{"steps": []}`,
+		`{"steps": [{"url": "/{{.id"}]}`,
+		`{"steps": [{"extract": {"id": "xpath:/a"}}]}`,
+		`{"steps": [{"url": "http://example.com/login"}]}`,
+		`{"steps": [{"url": "https://localhost/login"}]}`,
+	} {
+		f, _ := ioutil.TempFile("", "flow")
+		f.WriteString(s)
+		f.Close()
+		if _, err := loadFlow(f.Name(), base); err == nil {
+			t.Errorf("Expected error for flow %s", s)
+		}
+		os.Remove(f.Name())
+	}
+}
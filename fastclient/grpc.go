@@ -0,0 +1,134 @@
+package fastclient
+
+import (
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+var (
+	grpcProtoset = flag.String("grpcProtoset", "", "File with protobuf descriptor set (protoc --descriptor_set_out --include_imports) for gRPC method")
+	grpcMethod   = flag.String("grpcMethod", "", "Fully-qualified gRPC unary method to call, e.g. helloworld.Greeter/SayHello. "+
+		"Request body is used as JSON payload. gRPC status OK is considered as success")
+)
+
+// grpcStatusOK is a gRPC status code of successful call
+const grpcStatusOK = 0
+
+// grpcCodes are names of gRPC status codes
+var grpcCodes = []string{
+	"OK", "Canceled", "Unknown", "InvalidArgument", "DeadlineExceeded", "NotFound",
+	"AlreadyExists", "PermissionDenied", "ResourceExhausted", "FailedPrecondition",
+	"Aborted", "OutOfRange", "Unimplemented", "Internal", "Unavailable", "DataLoss", "Unauthenticated",
+}
+
+// isGRPC returns true if requests should be sent as gRPC calls
+func isGRPC() bool {
+	return *grpcMethod != ""
+}
+
+// grpcTransport sends gRPC unary calls over HTTP/2
+type grpcTransport struct {
+	*http2Client
+}
+
+// Status returns gRPC status code of response.
+// Status is taken from grpc-status trailer
+// or treated as Unknown if it is missing
+func (gt *grpcTransport) Status(resp *fasthttp.Response) (int, string) {
+	code := 2 // Unknown
+	if v := resp.Header.Peek("Grpc-Status"); len(v) > 0 {
+		if n, err := strconv.Atoi(string(v)); err == nil {
+			code = n
+		}
+	}
+	if code >= 0 && code < len(grpcCodes) {
+		return code, grpcCodes[code]
+	}
+	return code, strconv.Itoa(code)
+}
+
+// newGRPCRequest converts request with JSON payload
+// into gRPC call of grpcMethod
+func newGRPCRequest(req *fasthttp.Request) (*fasthttp.Request, error) {
+	md, err := findGRPCMethod(*grpcProtoset, *grpcMethod)
+	if err != nil {
+		return nil, err
+	}
+	msg := dynamicpb.NewMessage(md.Input())
+	if body := req.Body(); len(body) > 0 {
+		if err := protojson.Unmarshal(body, msg); err != nil {
+			return nil, fmt.Errorf("cannot parse payload as %s: %s", md.Input().FullName(), err)
+		}
+	}
+	payload, err := proto.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Length-Prefixed-Message: compression flag, 4-byte length, message
+	frame := make([]byte, 5+len(payload))
+	binary.BigEndian.PutUint32(frame[1:5], uint32(len(payload)))
+	copy(frame[5:], payload)
+
+	gr := new(fasthttp.Request)
+	req.CopyTo(gr)
+	uri := fmt.Sprintf("%s://%s/%s/%s", req.URI().Scheme(), req.URI().Host(), md.Parent().FullName(), md.Name())
+	gr.SetRequestURI(uri)
+	gr.Header.SetMethod("POST")
+	gr.Header.SetContentType("application/grpc")
+	gr.Header.Del("Accept-Encoding")
+	gr.Header.Set("TE", "trailers")
+	gr.SetBody(frame)
+	return gr, nil
+}
+
+// findGRPCMethod looks up method in descriptor set file.
+// Method could be set as pkg.Service/Method or pkg.Service.Method
+func findGRPCMethod(protoset, method string) (protoreflect.MethodDescriptor, error) {
+	data, err := ioutil.ReadFile(protoset)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read descriptor set: %s", err)
+	}
+	var fds descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(data, &fds); err != nil {
+		return nil, fmt.Errorf("cannot parse descriptor set %q: %s", protoset, err)
+	}
+	files, err := protodesc.NewFiles(&fds)
+	if err != nil {
+		return nil, fmt.Errorf("cannot build descriptors from %q: %s", protoset, err)
+	}
+
+	method = strings.TrimPrefix(method, "/")
+	n := strings.LastIndexAny(method, "/.")
+	if n < 0 {
+		return nil, fmt.Errorf("method %q must be fully-qualified, e.g. pkg.Service/Method", method)
+	}
+	d, err := files.FindDescriptorByName(protoreflect.FullName(method[:n]))
+	if err != nil {
+		return nil, fmt.Errorf("cannot find service %q: %s", method[:n], err)
+	}
+	sd, ok := d.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a service", method[:n])
+	}
+	md := sd.Methods().ByName(protoreflect.Name(method[n+1:]))
+	if md == nil {
+		return nil, fmt.Errorf("service %q has no method %q", method[:n], method[n+1:])
+	}
+	if md.IsStreamingClient() || md.IsStreamingServer() {
+		return nil, fmt.Errorf("method %q is not unary", method)
+	}
+	return md, nil
+}
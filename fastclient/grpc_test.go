@@ -0,0 +1,100 @@
+package fastclient
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func writeProtoset(t *testing.T) string {
+	fds := &descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{{
+			Name:    proto.String("test.proto"),
+			Package: proto.String("test"),
+			Syntax:  proto.String("proto3"),
+			MessageType: []*descriptorpb.DescriptorProto{{
+				Name: proto.String("HelloRequest"),
+				Field: []*descriptorpb.FieldDescriptorProto{{
+					Name:     proto.String("name"),
+					JsonName: proto.String("name"),
+					Number:   proto.Int32(1),
+					Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+					Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				}},
+			}},
+			Service: []*descriptorpb.ServiceDescriptorProto{{
+				Name: proto.String("Greeter"),
+				Method: []*descriptorpb.MethodDescriptorProto{{
+					Name:       proto.String("SayHello"),
+					InputType:  proto.String(".test.HelloRequest"),
+					OutputType: proto.String(".test.HelloRequest"),
+				}},
+			}},
+		}},
+	}
+	data, err := proto.Marshal(fds)
+	if err != nil {
+		t.Fatalf("cannot marshal descriptor set: %s", err)
+	}
+	f, err := ioutil.TempFile("", "protoset")
+	if err != nil {
+		t.Fatalf("cannot create temp file: %s", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(data); err != nil {
+		t.Fatalf("cannot write descriptor set: %s", err)
+	}
+	return f.Name()
+}
+
+func TestNewGRPCRequest(t *testing.T) {
+	path := writeProtoset(t)
+	defer os.Remove(path)
+	*grpcProtoset, *grpcMethod = path, "test.Greeter/SayHello"
+	defer func() { *grpcProtoset, *grpcMethod = "", "" }()
+
+	req := new(fasthttp.Request)
+	req.SetRequestURI("http://localhost:50051/")
+	req.SetBodyString(`{"name": "fasthttploader"}`)
+	gr, err := newGRPCRequest(req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if uri := gr.URI().String(); uri != "http://localhost:50051/test.Greeter/SayHello" {
+		t.Errorf("Unexpected uri. Got: %q; Expected: %q", uri, "http://localhost:50051/test.Greeter/SayHello")
+	}
+	if ct := string(gr.Header.ContentType()); ct != "application/grpc" {
+		t.Errorf("Unexpected content-type. Got: %q; Expected: %q", ct, "application/grpc")
+	}
+	body := gr.Body()
+	if len(body) < 5 || int(binary.BigEndian.Uint32(body[1:5])) != len(body)-5 {
+		t.Fatalf("Unexpected length-prefixed message: %v", body)
+	}
+	// field 1, wire type 2, length 14
+	if expected := "\x0a\x0efasthttploader"; string(body[5:]) != expected {
+		t.Errorf("Unexpected payload. Got: %q; Expected: %q", body[5:], expected)
+	}
+
+	*grpcMethod = "test.Greeter/SayGoodbye"
+	if _, err := newGRPCRequest(req); err == nil {
+		t.Errorf("Expected error for unknown method")
+	}
+}
+
+func TestGRPCStatus(t *testing.T) {
+	gt := &grpcTransport{}
+	resp := new(fasthttp.Response)
+	if code, label := gt.Status(resp); code != 2 || label != "Unknown" {
+		t.Errorf("Unexpected status of response without grpc-status. Got: %d %q; Expected: %d %q", code, label, 2, "Unknown")
+	}
+	resp.Header.Set("Grpc-Status", "14")
+	if code, label := gt.Status(resp); code != 14 || label != "Unavailable" {
+		t.Errorf("Unexpected status. Got: %d %q; Expected: %d %q", code, label, 14, "Unavailable")
+	}
+}
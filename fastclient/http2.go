@@ -0,0 +1,245 @@
+package fastclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	stderrors "errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/valyala/fasthttp"
+	"golang.org/x/net/http2"
+)
+
+var (
+	http2Enabled    = flag.Bool("http2", false, "Use HTTP/2 instead of HTTP/1.1. h2 over TLS is used for https urls and h2c with prior knowledge for http urls")
+	http2Conns      = flag.Int("http2Conns", 1, "Number of HTTP/2 connections")
+	http2MaxStreams = flag.Int("http2MaxStreams", 100, "Max number of concurrent streams per HTTP/2 connection")
+)
+
+// hopHeaders are connection-specific headers, which are prohibited in HTTP/2
+var hopHeaders = map[string]bool{
+	"Connection":        true,
+	"Keep-Alive":        true,
+	"Proxy-Connection":  true,
+	"Transfer-Encoding": true,
+	"Upgrade":           true,
+	"Host":              true,
+	"Content-Length":    true,
+}
+
+// http2Client sends requests over fixed number of HTTP/2 connections
+// with limited number of concurrent streams per connection
+type http2Client struct {
+	addr      string
+	isTLS     bool
+	tlsConfig *tls.Config
+	timeout   time.Duration
+	transport *http2.Transport
+	conns     []*http2Conn
+	next      uint32
+
+	// streams limits number of streams of all connections,
+	// so request waits only if all connections are saturated
+	streams chan struct{}
+}
+
+type http2Conn struct {
+	sync.Mutex
+	cc      *http2.ClientConn
+	streams chan struct{}
+}
+
+func newHTTP2Client(addr string, isTLS bool, timeout time.Duration, tlsConfig *tls.Config) *http2Client {
+	n := *http2Conns
+	if n < 1 {
+		n = 1
+	}
+	streams := *http2MaxStreams
+	if streams < 1 {
+		streams = 1
+	}
+	tlsConfig = tlsConfig.Clone()
+	tlsConfig.NextProtos = []string{http2.NextProtoTLS}
+	hc := &http2Client{
+		addr:      addr,
+		isTLS:     isTLS,
+		tlsConfig: tlsConfig,
+		timeout:   timeout,
+		transport: &http2.Transport{
+			AllowHTTP:                  true,
+			StrictMaxConcurrentStreams: true,
+		},
+		conns:   make([]*http2Conn, n),
+		streams: make(chan struct{}, n*streams),
+	}
+	for i := range hc.conns {
+		hc.conns[i] = &http2Conn{streams: make(chan struct{}, streams)}
+	}
+	return hc
+}
+
+// Do sends req over one of connections and fills resp.
+// Blocks while all streams of all connections are busy
+func (hc *http2Client) Do(req *fasthttp.Request, resp *fasthttp.Response) error {
+	conn := hc.acquire()
+	defer hc.release(conn)
+
+	cc, err := hc.clientConn(conn)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), hc.timeout)
+	defer cancel()
+	hr, err := acquireHTTPRequest(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	streamsOpen.Inc()
+	defer streamsOpen.Dec()
+	streamsSum.Inc()
+	res, err := cc.RoundTrip(hr)
+	if err != nil {
+		return hc.streamError(ctx, err)
+	}
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return hc.streamError(ctx, err)
+	}
+
+	resp.Reset()
+	resp.SetStatusCode(res.StatusCode)
+	for k, vv := range res.Header {
+		for _, v := range vv {
+			resp.Header.Add(k, v)
+		}
+	}
+	// trailers are available only after body was read
+	for k, vv := range res.Trailer {
+		for _, v := range vv {
+			resp.Header.Add(k, v)
+		}
+	}
+	resp.SetBody(body)
+	return nil
+}
+
+// acquire returns the first connection with free stream,
+// starting from the next one by turn
+func (hc *http2Client) acquire() *http2Conn {
+	hc.streams <- struct{}{}
+	// holding of client stream guarantees that
+	// at least one connection has free stream
+	n := uint32(len(hc.conns))
+	start := atomic.AddUint32(&hc.next, 1)
+	for i := uint32(0); ; i++ {
+		conn := hc.conns[(start+i)%n]
+		select {
+		case conn.streams <- struct{}{}:
+			return conn
+		default:
+		}
+	}
+}
+
+func (hc *http2Client) release(conn *http2Conn) {
+	<-conn.streams
+	<-hc.streams
+}
+
+func (hc *http2Client) streamError(ctx context.Context, err error) error {
+	if ctx.Err() == context.DeadlineExceeded {
+		return fasthttp.ErrTimeout
+	}
+	var se http2.StreamError
+	if stderrors.As(err, &se) {
+		streamErrors.Inc()
+	}
+	return err
+}
+
+// clientConn returns alive connection or establishes a new one
+func (hc *http2Client) clientConn(conn *http2Conn) (*http2.ClientConn, error) {
+	conn.Lock()
+	defer conn.Unlock()
+
+	if conn.cc != nil && conn.cc.CanTakeNewRequest() {
+		return conn.cc, nil
+	}
+	if conn.cc != nil {
+		// connection could be shared with streams in flight,
+		// so it is closed after they are finished
+		go hc.shutdown(conn.cc)
+		conn.cc = nil
+	}
+
+	c, err := hc.dial()
+	if err != nil {
+		return nil, err
+	}
+	cc, err := hc.transport.NewClientConn(c)
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	conn.cc = cc
+	return cc, nil
+}
+
+// shutdown closes cc after its streams in flight are finished
+// or request timeout is passed. GOAWAY isn't sent, since servers
+// may abort streams shortly after receiving it
+func (hc *http2Client) shutdown(cc *http2.ClientConn) {
+	deadline := time.Now().Add(hc.timeout)
+	for time.Now().Before(deadline) {
+		if st := cc.State(); st.Closed || st.StreamsActive+st.StreamsReserved+st.StreamsPending == 0 {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	cc.Close()
+}
+
+func (hc *http2Client) dial() (net.Conn, error) {
+	if !hc.isTLS {
+		return dial(hc.addr)
+	}
+
+	c, err := dialTLS(hc.addr, hc.tlsConfig, hc.timeout)
+	if err != nil {
+		return nil, err
+	}
+	if p := c.(*tls.Conn).ConnectionState().NegotiatedProtocol; p != http2.NextProtoTLS {
+		c.Close()
+		return nil, fmt.Errorf("server doesn't support HTTP/2; negotiated protocol is %q", p)
+	}
+	return c, nil
+}
+
+// acquireHTTPRequest converts fasthttp.Request to http.Request
+func acquireHTTPRequest(ctx context.Context, req *fasthttp.Request) (*http.Request, error) {
+	hr, err := http.NewRequest(string(req.Header.Method()), req.URI().String(), bytes.NewReader(req.Body()))
+	if err != nil {
+		return nil, err
+	}
+	hr = hr.WithContext(ctx)
+	hr.Host = string(req.Header.Host())
+	req.Header.VisitAll(func(k, v []byte) {
+		key := http.CanonicalHeaderKey(string(k))
+		if hopHeaders[key] {
+			return
+		}
+		hr.Header.Add(key, string(v))
+	})
+	return hr, nil
+}
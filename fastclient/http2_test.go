@@ -0,0 +1,171 @@
+package fastclient
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/valyala/fasthttp"
+	"golang.org/x/net/http2"
+)
+
+// newH2CServer returns server which accepts HTTP/2 with prior knowledge.
+// Requests to /block wait till release is closed
+func newH2CServer(release chan struct{}) *httptest.Server {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/block" {
+			<-release
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("X-Proto", r.Proto)
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, "%s %s %s", r.Method, r.Host, body)
+	}))
+	srv.EnableHTTP2 = true
+	var protocols http.Protocols
+	protocols.SetUnencryptedHTTP2(true)
+	srv.Config.Protocols = &protocols
+	srv.Start()
+	return srv
+}
+
+func TestHTTP2RoundTrip(t *testing.T) {
+	release := make(chan struct{})
+	srv := newH2CServer(release)
+	defer srv.Close()
+	defer close(release)
+
+	addr := strings.TrimPrefix(srv.URL, "http://")
+	hc := newHTTP2Client(addr, false, time.Second, &tls.Config{})
+	req := new(fasthttp.Request)
+	req.SetRequestURI(srv.URL + "/echo")
+	req.Header.SetMethod("POST")
+	req.Header.Set("Connection", "keep-alive")
+	req.SetBodyString("hello")
+	resp := new(fasthttp.Response)
+	if err := hc.Do(req, resp); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if resp.StatusCode() != fasthttp.StatusCreated {
+		t.Errorf("Unexpected status code. Got: %d; Expected: %d", resp.StatusCode(), fasthttp.StatusCreated)
+	}
+	if proto := string(resp.Header.Peek("X-Proto")); proto != "HTTP/2.0" {
+		t.Errorf("Unexpected protocol. Got: %q; Expected: %q", proto, "HTTP/2.0")
+	}
+	if body, expected := string(resp.Body()), "POST "+addr+" hello"; body != expected {
+		t.Errorf("Unexpected body. Got: %q; Expected: %q", body, expected)
+	}
+}
+
+func TestHTTP2FreeConnection(t *testing.T) {
+	*http2Conns, *http2MaxStreams = 2, 1
+	defer func() { *http2Conns, *http2MaxStreams = 1, 100 }()
+	release := make(chan struct{})
+	srv := newH2CServer(release)
+	defer srv.Close()
+
+	hc := newHTTP2Client(strings.TrimPrefix(srv.URL, "http://"), false, 5*time.Second, &tls.Config{})
+	blocked := make(chan error, 1)
+	go func() {
+		req := new(fasthttp.Request)
+		req.SetRequestURI(srv.URL + "/block")
+		blocked <- hc.Do(req, new(fasthttp.Response))
+	}()
+	// wait till blocked request takes its stream
+	for len(hc.streams) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// requests must be sent over connection with free stream
+	// instead of waiting for the saturated one
+	done := make(chan error, 1)
+	go func() {
+		req := new(fasthttp.Request)
+		req.SetRequestURI(srv.URL + "/")
+		for i := 0; i < 4; i++ {
+			if err := hc.Do(req, new(fasthttp.Response)); err != nil {
+				done <- err
+				return
+			}
+		}
+		done <- nil
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Requests were blocked by saturated connection")
+	}
+
+	close(release)
+	if err := <-blocked; err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+}
+
+func TestHTTP2StreamError(t *testing.T) {
+	hc := newHTTP2Client("localhost:80", false, time.Second, &tls.Config{})
+	before := (*Client)(nil).StreamErrors()
+	err := fmt.Errorf("wrapped: %w", http2.StreamError{StreamID: 1, Code: http2.ErrCodeRefusedStream})
+	if hc.streamError(t.Context(), err) != err {
+		t.Errorf("Expected error to be returned as is")
+	}
+	if n := (*Client)(nil).StreamErrors() - before; n != 1 {
+		t.Errorf("Unexpected number of stream errors. Got: %d; Expected: %d", n, 1)
+	}
+}
+
+func TestHTTP2UnusableConnection(t *testing.T) {
+	*http2MaxStreams = 2
+	defer func() { *http2MaxStreams = 100 }()
+	release := make(chan struct{})
+	srv := newH2CServer(release)
+	defer srv.Close()
+
+	hc := newHTTP2Client(strings.TrimPrefix(srv.URL, "http://"), false, 5*time.Second, &tls.Config{})
+	blocked := make(chan error, 1)
+	go func() {
+		req := new(fasthttp.Request)
+		req.SetRequestURI(srv.URL + "/block")
+		blocked <- hc.Do(req, new(fasthttp.Response))
+	}()
+	for len(hc.streams) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	var cc *http2.ClientConn
+	for cc == nil {
+		hc.conns[0].Lock()
+		cc = hc.conns[0].cc
+		hc.conns[0].Unlock()
+		time.Sleep(time.Millisecond)
+	}
+
+	// connection stops taking new requests while stream is in flight
+	cc.SetDoNotReuse()
+
+	// new request is sent over new connection
+	req := new(fasthttp.Request)
+	req.SetRequestURI(srv.URL + "/")
+	if err := hc.Do(req, new(fasthttp.Response)); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	hc.conns[0].Lock()
+	replaced := hc.conns[0].cc != cc
+	hc.conns[0].Unlock()
+	if !replaced {
+		t.Errorf("Expected unusable connection to be replaced")
+	}
+
+	// stream in flight isn't aborted
+	close(release)
+	if err := <-blocked; err != nil {
+		t.Fatalf("Unexpected error of stream in flight: %s", err)
+	}
+}
@@ -0,0 +1,90 @@
+package fastclient
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync/atomic"
+	"syscall"
+)
+
+var localAddrs localAddrList
+
+func init() {
+	flag.Var(&localAddrs, "localAddrs", "Comma-separated list of local IPs to bind outgoing connections to by turn. "+
+		"Helps to avoid ephemeral ports exhaustion at high number of connections")
+}
+
+// errPortsExhausted is returned instead of EADDRNOTAVAIL,
+// so all such errors are grouped in errors distribution
+var errPortsExhausted = fmt.Errorf("cannot assign requested address: local ports are exhausted")
+
+// localAddrList is a list of local addresses to bind connections to
+type localAddrList struct {
+	addrs []*net.TCPAddr
+
+	// addresses are rotated within family of remote address,
+	// since IPv4 address can't be bound to IPv6 connection and vice versa
+	ipv4, ipv6 localAddrRing
+}
+
+type localAddrRing struct {
+	addrs []*net.TCPAddr
+	next  uint32
+}
+
+// String implements flag.Value
+func (la *localAddrList) String() string {
+	var s []string
+	for _, a := range la.addrs {
+		s = append(s, a.IP.String())
+	}
+	return strings.Join(s, ",")
+}
+
+// Set implements flag.Value
+func (la *localAddrList) Set(value string) error {
+	for _, v := range strings.Split(value, ",") {
+		ip := net.ParseIP(strings.TrimSpace(v))
+		if ip == nil {
+			return fmt.Errorf("cannot parse IP %q", v)
+		}
+		a := &net.TCPAddr{IP: ip}
+		la.addrs = append(la.addrs, a)
+		if ip.To4() != nil {
+			la.ipv4.addrs = append(la.ipv4.addrs, a)
+		} else {
+			la.ipv6.addrs = append(la.ipv6.addrs, a)
+		}
+	}
+	return nil
+}
+
+// get returns next local address of the same family as remote IP
+// or nil if list is empty
+func (la *localAddrList) get(remote string) (net.Addr, error) {
+	if len(la.addrs) == 0 {
+		return nil, nil
+	}
+	r := &la.ipv6
+	if ip := net.ParseIP(remote); ip != nil && ip.To4() != nil {
+		r = &la.ipv4
+	}
+	if len(r.addrs) == 0 {
+		return nil, fmt.Errorf("no local address of the same family as %s in -localAddrs", remote)
+	}
+	return r.addrs[int(atomic.AddUint32(&r.next, 1)%uint32(len(r.addrs)))], nil
+}
+
+// isAddrNotAvail returns true if err is caused by EADDRNOTAVAIL
+func isAddrNotAvail(err error) bool {
+	if oe, ok := err.(*net.OpError); ok {
+		err = oe.Err
+	}
+	if se, ok := err.(*os.SyscallError); ok {
+		err = se.Err
+	}
+	return err == syscall.EADDRNOTAVAIL
+}
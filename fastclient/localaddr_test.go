@@ -0,0 +1,53 @@
+package fastclient
+
+import (
+	"testing"
+)
+
+func TestLocalAddrs(t *testing.T) {
+	var la localAddrList
+	if a, err := la.get("127.0.0.1"); a != nil || err != nil {
+		t.Fatalf("Expected no local address for empty list. Got: %v, %v", a, err)
+	}
+	if err := la.Set("127.0.0.1, ::1,127.0.0.2"); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if s := la.String(); s != "127.0.0.1,::1,127.0.0.2" {
+		t.Errorf("Unexpected string. Got: %q; Expected: %q", s, "127.0.0.1,::1,127.0.0.2")
+	}
+
+	// addresses are rotated within family of remote address
+	seen := make(map[string]int)
+	for i := 0; i < 4; i++ {
+		a, err := la.get("10.0.0.1")
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		seen[a.String()]++
+	}
+	if seen["127.0.0.1:0"] != 2 || seen["127.0.0.2:0"] != 2 {
+		t.Errorf("Expected IPv4 addresses to be used by turn. Got: %v", seen)
+	}
+	a, err := la.get("fe80::1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if a.String() != "[::1]:0" {
+		t.Errorf("Unexpected address for IPv6 target. Got: %q; Expected: %q", a, "[::1]:0")
+	}
+
+	var v4 localAddrList
+	if err := v4.Set("127.0.0.1"); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if _, err := v4.get("::1"); err == nil {
+		t.Errorf("Expected error for IPv6 target without IPv6 local addresses")
+	}
+
+	for _, v := range []string{"", "localhost", "127.0.0.1,", "127.0.0.256"} {
+		var la localAddrList
+		if err := la.Set(v); err == nil {
+			t.Errorf("Expected error for %q", v)
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package fastclient
 
 import (
+	"math"
+	"sort"
+
 	"github.com/prometheus/client_golang/prometheus"
 	dto "github.com/prometheus/client_model/go"
 )
@@ -11,6 +14,8 @@ var (
 	errorMessages   *prometheus.CounterVec
 	requestDuration prometheus.Summary
 
+	recentRequestDuration prometheus.Summary
+
 	timeouts       prometheus.Counter
 	errors         prometheus.Counter
 	requestSum     prometheus.Counter
@@ -20,8 +25,72 @@ var (
 	bytesRead      prometheus.Counter
 	writeError     prometheus.Counter
 	readError      prometheus.Counter
+
+	assertionFailures *prometheus.CounterVec
+
+	ipConnections  *prometheus.CounterVec
+	ipBytesWritten *prometheus.CounterVec
+	ipBytesRead    *prometheus.CounterVec
+
+	dnsDuration     prometheus.Summary
+	connectDuration prometheus.Summary
+	ttfbDuration    prometheus.Summary
+	bodyDuration    prometheus.Summary
+
+	logins        prometheus.Counter
+	loginErrors   prometheus.Counter
+	sessionResets prometheus.Counter
+
+	scriptErrors prometheus.Counter
+
+	authFailures   prometheus.Counter
+	tokenRefreshes prometheus.Counter
+	tokenErrors    prometheus.Counter
+
+	flowStepRequests *prometheus.CounterVec
+	flowStepFailures *prometheus.CounterVec
+	flowStepDuration *prometheus.SummaryVec
+
+	redirects        prometheus.Counter
+	firstHopDuration prometheus.Summary
+
+	newConns       prometheus.Counter
+	portsExhausted prometheus.Counter
+	proxyErrors    prometheus.Counter
+
+	tlsHandshakeErrors   prometheus.Counter
+	tlsHandshakes        prometheus.Counter
+	tlsResumedHandshakes prometheus.Counter
+	tlsHandshakeDuration prometheus.Summary
+
+	streamsOpen  prometheus.Gauge
+	streamsSum   prometheus.Counter
+	streamErrors prometheus.Counter
+
+	wsConnectDuration prometheus.Summary
+	wsDisconnects     *prometheus.CounterVec
+
+	eventStreamTTFB     prometheus.Summary
+	eventStreamDuration prometheus.Summary
+	eventGap            prometheus.Summary
+	eventStreamDrops    prometheus.Counter
+	events              prometheus.Counter
 )
 
+// objectives are quantiles of latency metrics with their allowed errors
+var objectives = map[float64]float64{0.5: 0.05, 0.75: 0.025, 0.8: 0.02, 0.9: 0.01, 0.95: 0.005, 0.99: 0.001}
+
+// Quantiles returns sorted list of latency quantiles
+// which are available in RequestDuration and RecentRequestDuration
+func Quantiles() []float64 {
+	result := make([]float64, 0, len(objectives))
+	for q := range objectives {
+		result = append(result, q)
+	}
+	sort.Float64s(result)
+	return result
+}
+
 func initMetrics() {
 	statusCodes = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -71,7 +140,17 @@ func initMetrics() {
 		prometheus.SummaryOpts{
 			Name:       "request_duration",
 			Help:       "Latency of sent requests",
-			Objectives: map[float64]float64{0.5: 0.05, 0.75: 0.025, 0.8: 0.02, 0.9: 0.01, 0.99: 0.001},
+			Objectives: objectives,
+		},
+	)
+
+	recentRequestDuration = prometheus.NewSummary(
+		prometheus.SummaryOpts{
+			Name:       "recent_request_duration",
+			Help:       "Latency of requests sent during latencyWindow",
+			Objectives: objectives,
+			MaxAge:     *latencyWindow,
+			AgeBuckets: 2,
 		},
 	)
 
@@ -116,6 +195,284 @@ func initMetrics() {
 			Help: "Number of errors while reading",
 		},
 	)
+
+	assertionFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "assertion_failures",
+			Help: "Distribution of failed assertions",
+		},
+		[]string{"assertion"},
+	)
+
+	ipConnections = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ip_connections",
+			Help: "Distribution of connections by IP",
+		},
+		[]string{"ip"},
+	)
+
+	ipBytesWritten = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ip_bytes_written",
+			Help: "Distribution of written bytes by IP",
+		},
+		[]string{"ip"},
+	)
+
+	ipBytesRead = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ip_bytes_read",
+			Help: "Distribution of read bytes by IP",
+		},
+		[]string{"ip"},
+	)
+
+	dnsDuration = prometheus.NewSummary(
+		prometheus.SummaryOpts{
+			Name:       "dns_duration",
+			Help:       "Latency of DNS lookup per connection, including cache hits",
+			Objectives: objectives,
+		},
+	)
+
+	connectDuration = prometheus.NewSummary(
+		prometheus.SummaryOpts{
+			Name:       "connect_duration",
+			Help:       "Latency of TCP connection establishing",
+			Objectives: objectives,
+		},
+	)
+
+	ttfbDuration = prometheus.NewSummary(
+		prometheus.SummaryOpts{
+			Name:       "ttfb_duration",
+			Help:       "Time from sending request till first byte of response",
+			Objectives: objectives,
+		},
+	)
+
+	bodyDuration = prometheus.NewSummary(
+		prometheus.SummaryOpts{
+			Name:       "body_duration",
+			Help:       "Time from first till last byte of response",
+			Objectives: objectives,
+		},
+	)
+
+	logins = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "logins",
+			Help: "Number of sent login requests",
+		},
+	)
+
+	loginErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "login_errors",
+			Help: "Number of failed login requests",
+		},
+	)
+
+	sessionResets = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "session_resets",
+			Help: "Number of reset sessions",
+		},
+	)
+
+	authFailures = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "auth_failures",
+			Help: "Number of requests rejected with 401 or 403 after credentials refresh",
+		},
+	)
+
+	tokenRefreshes = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "token_refreshes",
+			Help: "Number of obtained OAuth2 tokens",
+		},
+	)
+
+	tokenErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "token_errors",
+			Help: "Number of failed OAuth2 token requests",
+		},
+	)
+
+	scriptErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "script_errors",
+			Help: "Number of errors thrown by script hooks",
+		},
+	)
+
+	flowStepRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "flow_step_requests",
+			Help: "Number of requests by flow step",
+		},
+		[]string{"step"},
+	)
+
+	flowStepFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "flow_step_failures",
+			Help: "Number of failed requests by flow step",
+		},
+		[]string{"step"},
+	)
+
+	flowStepDuration = prometheus.NewSummaryVec(
+		prometheus.SummaryOpts{
+			Name:       "flow_step_duration",
+			Help:       "Latency of requests by flow step",
+			Objectives: objectives,
+		},
+		[]string{"step"},
+	)
+
+	redirects = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "redirects",
+			Help: "Number of followed redirects",
+		},
+	)
+
+	firstHopDuration = prometheus.NewSummary(
+		prometheus.SummaryOpts{
+			Name:       "first_hop_duration",
+			Help:       "Latency of the first request in redirect chain",
+			Objectives: objectives,
+		},
+	)
+
+	newConns = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "new_connections",
+			Help: "Number of established connections",
+		},
+	)
+
+	portsExhausted = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "ports_exhausted",
+			Help: "Number of connections failed due to EADDRNOTAVAIL",
+		},
+	)
+
+	proxyErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "proxy_errors",
+			Help: "Number of failed connections to proxy",
+		},
+	)
+
+	tlsHandshakeErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "tls_handshake_errors",
+			Help: "Number of failed TLS handshakes",
+		},
+	)
+
+	tlsHandshakes = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "tls_handshakes",
+			Help: "Number of successful TLS handshakes",
+		},
+	)
+
+	tlsResumedHandshakes = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "tls_resumed_handshakes",
+			Help: "Number of TLS handshakes with resumed session",
+		},
+	)
+
+	tlsHandshakeDuration = prometheus.NewSummary(
+		prometheus.SummaryOpts{
+			Name:       "tls_handshake_duration",
+			Help:       "Latency of TLS handshake",
+			Objectives: objectives,
+		},
+	)
+
+	streamsOpen = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "streams_open",
+			Help: "Number of open HTTP/2 streams",
+		},
+	)
+
+	streamsSum = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "streams_sum",
+			Help: "Total number of opened HTTP/2 streams",
+		},
+	)
+
+	streamErrors = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "stream_errors",
+			Help: "Number of HTTP/2 streams ended with error",
+		},
+	)
+
+	wsConnectDuration = prometheus.NewSummary(
+		prometheus.SummaryOpts{
+			Name:       "ws_connect_duration",
+			Help:       "Latency of WebSocket connection establishing",
+			Objectives: objectives,
+		},
+	)
+
+	wsDisconnects = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ws_disconnects",
+			Help: "Distribution of WebSocket connections closed due to error by reason",
+		},
+		[]string{"reason"},
+	)
+
+	eventStreamTTFB = prometheus.NewSummary(
+		prometheus.SummaryOpts{
+			Name:       "event_stream_ttfb",
+			Help:       "Time to first byte of event stream response",
+			Objectives: objectives,
+		},
+	)
+
+	eventStreamDuration = prometheus.NewSummary(
+		prometheus.SummaryOpts{
+			Name:       "event_stream_duration",
+			Help:       "Lifetime of event stream connections",
+			Objectives: objectives,
+		},
+	)
+
+	eventGap = prometheus.NewSummary(
+		prometheus.SummaryOpts{
+			Name:       "event_gap",
+			Help:       "Time between events received by worker",
+			Objectives: objectives,
+		},
+	)
+
+	eventStreamDrops = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "event_stream_drops",
+			Help: "Number of event streams ended before lifetime",
+		},
+	)
+
+	events = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "events",
+			Help: "Total number of received events",
+		},
+	)
 }
 
 func registerMetrics() {
@@ -124,6 +481,7 @@ func registerMetrics() {
 	prometheus.MustRegister(errors)
 	prometheus.MustRegister(requestSum)
 	prometheus.MustRegister(requestDuration)
+	prometheus.MustRegister(recentRequestDuration)
 	prometheus.MustRegister(connOpen)
 	prometheus.MustRegister(connError)
 	prometheus.MustRegister(bytesWritten)
@@ -132,6 +490,43 @@ func registerMetrics() {
 	prometheus.MustRegister(readError)
 	prometheus.MustRegister(statusCodes)
 	prometheus.MustRegister(errorMessages)
+	prometheus.MustRegister(assertionFailures)
+	prometheus.MustRegister(ipConnections)
+	prometheus.MustRegister(ipBytesWritten)
+	prometheus.MustRegister(ipBytesRead)
+	prometheus.MustRegister(dnsDuration)
+	prometheus.MustRegister(connectDuration)
+	prometheus.MustRegister(ttfbDuration)
+	prometheus.MustRegister(bodyDuration)
+	prometheus.MustRegister(logins)
+	prometheus.MustRegister(loginErrors)
+	prometheus.MustRegister(sessionResets)
+	prometheus.MustRegister(scriptErrors)
+	prometheus.MustRegister(authFailures)
+	prometheus.MustRegister(tokenRefreshes)
+	prometheus.MustRegister(tokenErrors)
+	prometheus.MustRegister(flowStepRequests)
+	prometheus.MustRegister(flowStepFailures)
+	prometheus.MustRegister(flowStepDuration)
+	prometheus.MustRegister(redirects)
+	prometheus.MustRegister(firstHopDuration)
+	prometheus.MustRegister(newConns)
+	prometheus.MustRegister(portsExhausted)
+	prometheus.MustRegister(proxyErrors)
+	prometheus.MustRegister(tlsHandshakeErrors)
+	prometheus.MustRegister(tlsHandshakes)
+	prometheus.MustRegister(tlsResumedHandshakes)
+	prometheus.MustRegister(tlsHandshakeDuration)
+	prometheus.MustRegister(streamsOpen)
+	prometheus.MustRegister(streamsSum)
+	prometheus.MustRegister(streamErrors)
+	prometheus.MustRegister(wsConnectDuration)
+	prometheus.MustRegister(wsDisconnects)
+	prometheus.MustRegister(eventStreamTTFB)
+	prometheus.MustRegister(eventStreamDuration)
+	prometheus.MustRegister(eventGap)
+	prometheus.MustRegister(eventStreamDrops)
+	prometheus.MustRegister(events)
 }
 
 func unregisterMetrics() {
@@ -140,6 +535,7 @@ func unregisterMetrics() {
 	prometheus.Unregister(requestSum)
 	prometheus.Unregister(requestSuccess)
 	prometheus.Unregister(requestDuration)
+	prometheus.Unregister(recentRequestDuration)
 	prometheus.Unregister(connOpen)
 	prometheus.Unregister(connError)
 	prometheus.Unregister(bytesWritten)
@@ -148,6 +544,43 @@ func unregisterMetrics() {
 	prometheus.Unregister(readError)
 	prometheus.Unregister(statusCodes)
 	prometheus.Unregister(errorMessages)
+	prometheus.Unregister(assertionFailures)
+	prometheus.Unregister(ipConnections)
+	prometheus.Unregister(ipBytesWritten)
+	prometheus.Unregister(ipBytesRead)
+	prometheus.Unregister(dnsDuration)
+	prometheus.Unregister(connectDuration)
+	prometheus.Unregister(ttfbDuration)
+	prometheus.Unregister(bodyDuration)
+	prometheus.Unregister(logins)
+	prometheus.Unregister(loginErrors)
+	prometheus.Unregister(sessionResets)
+	prometheus.Unregister(scriptErrors)
+	prometheus.Unregister(authFailures)
+	prometheus.Unregister(tokenRefreshes)
+	prometheus.Unregister(tokenErrors)
+	prometheus.Unregister(flowStepRequests)
+	prometheus.Unregister(flowStepFailures)
+	prometheus.Unregister(flowStepDuration)
+	prometheus.Unregister(redirects)
+	prometheus.Unregister(firstHopDuration)
+	prometheus.Unregister(newConns)
+	prometheus.Unregister(portsExhausted)
+	prometheus.Unregister(proxyErrors)
+	prometheus.Unregister(tlsHandshakeErrors)
+	prometheus.Unregister(tlsHandshakes)
+	prometheus.Unregister(tlsResumedHandshakes)
+	prometheus.Unregister(tlsHandshakeDuration)
+	prometheus.Unregister(streamsOpen)
+	prometheus.Unregister(streamsSum)
+	prometheus.Unregister(streamErrors)
+	prometheus.Unregister(wsConnectDuration)
+	prometheus.Unregister(wsDisconnects)
+	prometheus.Unregister(eventStreamTTFB)
+	prometheus.Unregister(eventStreamDuration)
+	prometheus.Unregister(eventGap)
+	prometheus.Unregister(eventStreamDrops)
+	prometheus.Unregister(events)
 }
 
 func flushMetrics() {
@@ -199,9 +632,189 @@ func (*Client) ConnOpen() uint64 {
 	return uint64(*m.Gauge.Value)
 }
 
+// Phases are names of request phases in order of their execution
+var Phases = []string{"dns", "connect", "tls", "ttfb", "body"}
+
+// PhaseDuration returns median latency of every request phase.
+// Phase which wasn't observed yet has zero latency
+func (*Client) PhaseDuration() map[string]float64 {
+	summaries := []prometheus.Summary{dnsDuration, connectDuration, tlsHandshakeDuration, ttfbDuration, bodyDuration}
+	result := make(map[string]float64, len(Phases))
+	for i, s := range summaries {
+		v := quantiles(s)[0.5]
+		if math.IsNaN(v) {
+			v = 0
+		}
+		result[Phases[i]] = v
+	}
+	return result
+}
+
+// Logins returns value of logins-metric
+func (*Client) Logins() uint64 {
+	logins.Write(m)
+	return uint64(*m.Counter.Value)
+}
+
+// LoginErrors returns value of loginErrors-metric
+func (*Client) LoginErrors() uint64 {
+	loginErrors.Write(m)
+	return uint64(*m.Counter.Value)
+}
+
+// SessionResets returns value of sessionResets-metric
+func (*Client) SessionResets() uint64 {
+	sessionResets.Write(m)
+	return uint64(*m.Counter.Value)
+}
+
+// Redirects returns value of redirects-metric
+func (*Client) Redirects() uint64 {
+	redirects.Write(m)
+	return uint64(*m.Counter.Value)
+}
+
+// FirstHopDuration returns quantiles of the first hop latency of redirect chains
+func (*Client) FirstHopDuration() map[float64]float64 {
+	return quantiles(firstHopDuration)
+}
+
+// NewConnections returns value of newConns-metric
+func (*Client) NewConnections() uint64 {
+	newConns.Write(m)
+	return uint64(*m.Counter.Value)
+}
+
+// PortsExhausted returns value of portsExhausted-metric
+func (*Client) PortsExhausted() uint64 {
+	portsExhausted.Write(m)
+	return uint64(*m.Counter.Value)
+}
+
+// ProxyErrors returns value of proxyErrors-metric
+func (*Client) ProxyErrors() uint64 {
+	proxyErrors.Write(m)
+	return uint64(*m.Counter.Value)
+}
+
+// AuthFailures returns value of authFailures-metric
+func (*Client) AuthFailures() uint64 {
+	authFailures.Write(m)
+	return uint64(*m.Counter.Value)
+}
+
+// TokenErrors returns value of tokenErrors-metric
+func (*Client) TokenErrors() uint64 {
+	tokenErrors.Write(m)
+	return uint64(*m.Counter.Value)
+}
+
+// ScriptErrors returns value of scriptErrors-metric
+func (*Client) ScriptErrors() uint64 {
+	scriptErrors.Write(m)
+	return uint64(*m.Counter.Value)
+}
+
+// HandshakeErrors returns value of tlsHandshakeErrors-metric
+func (*Client) HandshakeErrors() uint64 {
+	tlsHandshakeErrors.Write(m)
+	return uint64(*m.Counter.Value)
+}
+
+// Handshakes returns value of tlsHandshakes-metric
+func (*Client) Handshakes() uint64 {
+	tlsHandshakes.Write(m)
+	return uint64(*m.Counter.Value)
+}
+
+// ResumedHandshakes returns value of tlsResumedHandshakes-metric
+func (*Client) ResumedHandshakes() uint64 {
+	tlsResumedHandshakes.Write(m)
+	return uint64(*m.Counter.Value)
+}
+
+// HandshakeDuration returns quantiles of TLS handshake latency
+func (*Client) HandshakeDuration() map[float64]float64 {
+	return quantiles(tlsHandshakeDuration)
+}
+
+// StreamsOpen returns value of streamsOpen-metric
+func (*Client) StreamsOpen() uint64 {
+	streamsOpen.Write(m)
+	return uint64(*m.Gauge.Value)
+}
+
+// StreamsSum returns value of streamsSum-metric
+func (*Client) StreamsSum() uint64 {
+	streamsSum.Write(m)
+	return uint64(*m.Counter.Value)
+}
+
+// StreamErrors returns value of streamErrors-metric
+func (*Client) StreamErrors() uint64 {
+	streamErrors.Write(m)
+	return uint64(*m.Counter.Value)
+}
+
+// Disconnects returns total value of wsDisconnects-metric
+func (*Client) Disconnects() uint64 {
+	var n uint64
+	for _, v := range countersByLabel(wsDisconnects) {
+		n += v
+	}
+	return n
+}
+
+// DisconnectReasons returns map reason:count for wsDisconnects-metric
+func (*Client) DisconnectReasons() map[string]uint64 {
+	return countersByLabel(wsDisconnects)
+}
+
+// ConnectDuration returns map quantile:value for wsConnectDuration-metric
+func (*Client) ConnectDuration() map[float64]float64 {
+	return quantiles(wsConnectDuration)
+}
+
+// EventStreamDrops returns value of eventStreamDrops-metric
+func (*Client) EventStreamDrops() uint64 {
+	eventStreamDrops.Write(m)
+	return uint64(*m.Counter.Value)
+}
+
+// Events returns value of events-metric
+func (*Client) Events() uint64 {
+	events.Write(m)
+	return uint64(*m.Counter.Value)
+}
+
+// EventStreamTTFB returns map quantile:value for eventStreamTTFB-metric
+func (*Client) EventStreamTTFB() map[float64]float64 {
+	return quantiles(eventStreamTTFB)
+}
+
+// EventStreamDuration returns map quantile:value for eventStreamDuration-metric
+func (*Client) EventStreamDuration() map[float64]float64 {
+	return quantiles(eventStreamDuration)
+}
+
+// EventGap returns map quantile:value for eventGap-metric
+func (*Client) EventGap() map[float64]float64 {
+	return quantiles(eventGap)
+}
+
 // RequestDuration returns map quantile:value for requestDuration-metric
 func (*Client) RequestDuration() map[float64]float64 {
-	requestDuration.Write(m)
+	return quantiles(requestDuration)
+}
+
+// RecentRequestDuration returns map quantile:value for requests
+// which were sent during latencyWindow
+func (*Client) RecentRequestDuration() map[float64]float64 {
+	return quantiles(recentRequestDuration)
+}
+
+func quantiles(s prometheus.Summary) map[float64]float64 {
+	s.Write(m)
 	result := make(map[float64]float64, len(m.Summary.Quantile))
 	for _, v := range m.Summary.Quantile {
 		result[*v.Quantile] = *v.Value
@@ -234,3 +847,51 @@ func (c *Client) ErrorMessages() map[string]int {
 	}
 	return result
 }
+
+// AssertionFailures returns map assertion:value for assertionFailures-metric
+// where value is a number of responses failed assertion
+func (*Client) AssertionFailures() map[string]uint64 {
+	return countersByLabel(assertionFailures)
+}
+
+// IPTraffic contains amount of traffic sent to single IP
+type IPTraffic struct {
+	Connections  uint64
+	BytesWritten uint64
+	BytesRead    uint64
+}
+
+// IPTraffic returns map ip:traffic of IPs which received connections
+func (*Client) IPTraffic() map[string]IPTraffic {
+	conns := countersByLabel(ipConnections)
+	written := countersByLabel(ipBytesWritten)
+	read := countersByLabel(ipBytesRead)
+	result := make(map[string]IPTraffic, len(conns))
+	for ip, n := range conns {
+		result[ip] = IPTraffic{
+			Connections:  n,
+			BytesWritten: written[ip],
+			BytesRead:    read[ip],
+		}
+	}
+	return result
+}
+
+// countersByLabel returns values of vec counters
+// mapped by value of their single label
+func countersByLabel(vec *prometheus.CounterVec) map[string]uint64 {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		vec.Collect(ch)
+		close(ch)
+	}()
+	result := make(map[string]uint64)
+	for metric := range ch {
+		var d dto.Metric
+		if err := metric.Write(&d); err != nil {
+			continue
+		}
+		result[d.GetLabel()[0].GetValue()] = uint64(d.GetCounter().GetValue())
+	}
+	return result
+}
@@ -0,0 +1,181 @@
+package fastclient
+
+import (
+	"bufio"
+	"encoding/base64"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testProxy is in-process proxy, which tunnels connections to echo handler.
+// HTTP proxy responds with status, SOCKS5 proxy replies with code
+type testProxy struct {
+	ln     net.Listener
+	socks  bool
+	status int
+	code   byte
+}
+
+func newTestProxy(t *testing.T, socks bool) *testProxy {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	tp := &testProxy{ln: ln, socks: socks, status: http.StatusOK}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go tp.serve(conn)
+		}
+	}()
+	return tp
+}
+
+func (tp *testProxy) serve(conn net.Conn) {
+	defer conn.Close()
+	br := bufio.NewReader(conn)
+	if tp.connect(br, conn) {
+		io.Copy(conn, br)
+	}
+}
+
+func (tp *testProxy) connect(br *bufio.Reader, conn net.Conn) bool {
+	if !tp.socks {
+		req, err := http.ReadRequest(br)
+		if err != nil || req.Method != "CONNECT" || req.Host != "example.com:80" {
+			return false
+		}
+		auth := base64.StdEncoding.EncodeToString([]byte("user:pass"))
+		status := tp.status
+		if req.Header.Get("Proxy-Authorization") != "Basic "+auth {
+			status = http.StatusProxyAuthRequired
+		}
+		resp := &http.Response{StatusCode: status, ProtoMajor: 1, ProtoMinor: 1}
+		resp.Write(conn)
+		return status == http.StatusOK
+	}
+
+	// greeting: version, number of methods and methods
+	b := make([]byte, 2)
+	if _, err := io.ReadFull(br, b); err != nil {
+		return false
+	}
+	methods := make([]byte, b[1])
+	io.ReadFull(br, methods)
+	// username/password authentication is required
+	if !strings.Contains(string(methods), "\x02") {
+		conn.Write([]byte{5, 0xff})
+		return false
+	}
+	conn.Write([]byte{5, 2})
+	if _, err := io.ReadFull(br, b); err != nil {
+		return false
+	}
+	user := make([]byte, b[1])
+	io.ReadFull(br, user)
+	io.ReadFull(br, b[:1])
+	pass := make([]byte, b[0])
+	io.ReadFull(br, pass)
+	if string(user) != "user" || string(pass) != "pass" {
+		conn.Write([]byte{1, 1})
+		return false
+	}
+	conn.Write([]byte{1, 0})
+
+	// request: version, command, reserved, domain name and port
+	h := make([]byte, 5)
+	if _, err := io.ReadFull(br, h); err != nil || h[1] != 1 || h[3] != 3 {
+		return false
+	}
+	host := make([]byte, h[4]+2)
+	io.ReadFull(br, host)
+	code := tp.code
+	if string(host[:h[4]]) != "example.com" {
+		// host unreachable
+		code = 4
+	}
+	conn.Write([]byte{5, code, 0, 1, 127, 0, 0, 1, 0, 80})
+	return code == 0
+}
+
+func TestDialProxy(t *testing.T) {
+	defer func() { *proxyAddr, proxyURL = "", nil }()
+	testCases := []struct {
+		name   string
+		socks  bool
+		user   string
+		status int
+		code   byte
+		err    string
+	}{
+		{name: "connect", user: "user:pass"},
+		{name: "connect without auth", err: "407"},
+		{name: "connect with wrong auth", user: "user:wrong", err: "407"},
+		{name: "connect refused", user: "user:pass", status: http.StatusForbidden, err: "403"},
+		{name: "connect bad gateway", user: "user:pass", status: http.StatusBadGateway, err: "502"},
+		{name: "socks5", socks: true, user: "user:pass"},
+		{name: "socks5 without auth", socks: true, err: "authentication"},
+		{name: "socks5 with wrong auth", socks: true, user: "user:wrong", err: "authentication"},
+		{name: "socks5 not allowed", socks: true, user: "user:pass", code: 2, err: "not allowed"},
+		{name: "socks5 connection refused", socks: true, user: "user:pass", code: 5, err: "refused"},
+	}
+	for _, tc := range testCases {
+		tp := newTestProxy(t, tc.socks)
+		if tc.status != 0 {
+			tp.status = tc.status
+		}
+		tp.code = tc.code
+
+		scheme := "http://"
+		if tc.socks {
+			scheme = "socks5://"
+		}
+		*proxyAddr = scheme + tp.ln.Addr().String()
+		if tc.user != "" {
+			*proxyAddr = scheme + tc.user + "@" + tp.ln.Addr().String()
+		}
+		if err := initProxy(); err != nil {
+			t.Fatalf("%s: unexpected error: %s", tc.name, err)
+		}
+
+		before := (*Client)(nil).ProxyErrors()
+		conn, ip, err := dialProxy("example.com:80", time.Now().Add(time.Second))
+		if tc.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("%s: unexpected error. Got: %v; Expected: %q", tc.name, err, tc.err)
+			}
+			if n := (*Client)(nil).ProxyErrors() - before; n != 1 {
+				t.Errorf("%s: unexpected number of proxy errors. Got: %d; Expected: %d", tc.name, n, 1)
+			}
+			tp.ln.Close()
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", tc.name, err)
+		}
+		if ip != "127.0.0.1" {
+			t.Errorf("%s: unexpected IP. Got: %q; Expected: %q", tc.name, ip, "127.0.0.1")
+		}
+		conn.Write([]byte("ping"))
+		b := make([]byte, 4)
+		if _, err := io.ReadFull(conn, b); err != nil || string(b) != "ping" {
+			t.Errorf("%s: unexpected response through tunnel. Got: %q, %v; Expected: %q", tc.name, b, err, "ping")
+		}
+		conn.Close()
+		tp.ln.Close()
+	}
+
+	for _, v := range []string{"ftp://localhost:21", "://"} {
+		*proxyAddr = v
+		if err := initProxy(); err == nil {
+			t.Errorf("Expected error for proxy %q", v)
+		}
+	}
+}
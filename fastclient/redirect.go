@@ -0,0 +1,127 @@
+package fastclient
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/valyala/fasthttp"
+)
+
+var (
+	maxRedirects = flag.Int("maxRedirects", 0, "Max number of redirects to follow for HTTP/1.1 requests. "+
+		"Redirects aren't followed if 0. Latency of full redirect chain is measured along with latency of the first hop")
+	redirectCrossHost = flag.Bool("redirectCrossHost", false, "Follow redirects to other hosts. "+
+		"Separate connections pool is used for every host. Only redirects to the same host are followed if not set")
+)
+
+var errTooManyRedirects = fmt.Errorf("too many redirects")
+
+// redirecter follows redirects of HTTP/1.1 responses
+type redirecter struct {
+	// addr and isTLS identify host of requests
+	addr  string
+	isTLS bool
+
+	newTransport func(addr string, isTLS bool) Transport
+
+	mu    sync.Mutex
+	hosts map[string]Transport
+}
+
+// newRedirecter returns nil if redirects shouldn't be followed
+func newRedirecter(addr string, isTLS bool, newTransport func(addr string, isTLS bool) Transport) *redirecter {
+	if *maxRedirects <= 0 {
+		return nil
+	}
+	return &redirecter{
+		addr:         addr,
+		isTLS:        isTLS,
+		newTransport: newTransport,
+		hosts:        make(map[string]Transport),
+	}
+}
+
+// follow sends requests to Location of resp till response isn't redirect.
+// t is a transport of original request and hop is reused for every redirect
+func (rd *redirecter) follow(t Transport, req *fasthttp.Request, resp *fasthttp.Response, hop *fasthttp.Request) error {
+	for i := 0; isRedirect(resp.StatusCode()); i++ {
+		location := resp.Header.Peek("Location")
+		if len(location) == 0 {
+			return nil
+		}
+		if i == *maxRedirects {
+			return errTooManyRedirects
+		}
+		if i == 0 {
+			req.CopyTo(hop)
+		}
+
+		sc := resp.StatusCode()
+		hop.URI().UpdateBytes(location)
+		hop.Header.SetHostBytes(hop.URI().Host())
+		method := string(hop.Header.Method())
+		if sc == fasthttp.StatusSeeOther ||
+			(sc != fasthttp.StatusTemporaryRedirect && sc != fasthttp.StatusPermanentRedirect && method != "GET" && method != "HEAD") {
+			hop.Header.SetMethod("GET")
+			hop.ResetBody()
+		}
+
+		addr, isTLS := uriAddr(hop.URI())
+		if addr != rd.addr || isTLS != rd.isTLS {
+			if !*redirectCrossHost {
+				return nil
+			}
+			t = rd.transport(addr, isTLS)
+		}
+		redirects.Inc()
+		if err := t.Do(hop, resp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// transport returns transport for given host
+func (rd *redirecter) transport(addr string, isTLS bool) Transport {
+	key := addr
+	if isTLS {
+		key = "https://" + addr
+	}
+	rd.mu.Lock()
+	defer rd.mu.Unlock()
+	t, ok := rd.hosts[key]
+	if !ok {
+		t = rd.newTransport(addr, isTLS)
+		rd.hosts[key] = t
+	}
+	return t
+}
+
+func isRedirect(sc int) bool {
+	switch sc {
+	case fasthttp.StatusMovedPermanently, fasthttp.StatusFound, fasthttp.StatusSeeOther,
+		fasthttp.StatusTemporaryRedirect, fasthttp.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+// uriAddr returns host:port of uri and whether it requires TLS.
+// All uris have the same address if -unixSocket is set
+func uriAddr(uri *fasthttp.URI) (string, bool) {
+	isTLS := string(uri.Scheme()) == "https"
+	if *unixSocket != "" {
+		return unixAddrPrefix + *unixSocket, isTLS
+	}
+	host := string(uri.Host())
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		return host, isTLS
+	}
+	port := "80"
+	if isTLS {
+		port = "443"
+	}
+	return net.JoinHostPort(host, port), isTLS
+}
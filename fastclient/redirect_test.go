@@ -0,0 +1,75 @@
+package fastclient
+
+import (
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+// redirectTransport redirects requests according to paths map
+type redirectTransport struct {
+	paths map[string]string
+	hops  []string
+}
+
+func (rt *redirectTransport) Do(req *fasthttp.Request, resp *fasthttp.Response) error {
+	resp.Reset()
+	rt.hops = append(rt.hops, req.URI().String())
+	if location, ok := rt.paths[string(req.URI().Path())]; ok {
+		resp.SetStatusCode(fasthttp.StatusFound)
+		resp.Header.Set("Location", location)
+		return nil
+	}
+	resp.SetStatusCode(fasthttp.StatusOK)
+	return nil
+}
+
+func TestRedirecterFollow(t *testing.T) {
+	*maxRedirects = 2
+	defer func() { *maxRedirects = 0 }()
+
+	other := &redirectTransport{}
+	rd := newRedirecter("localhost:80", false, func(addr string, isTLS bool) Transport {
+		if addr != "example.com:80" || isTLS {
+			t.Fatalf("Unexpected host. Got: %q %v; Expected: %q %v", addr, isTLS, "example.com:80", false)
+		}
+		return other
+	})
+	rt := &redirectTransport{paths: map[string]string{
+		"/a":     "/b",
+		"/b":     "/c?d=1",
+		"/loop":  "/loop",
+		"/cross": "http://example.com/final",
+	}}
+
+	f := func(path string, hops int, expectedErr error) {
+		t.Helper()
+		rt.hops = rt.hops[:0]
+		req, hop, resp := new(fasthttp.Request), new(fasthttp.Request), new(fasthttp.Response)
+		req.SetRequestURI("http://localhost" + path)
+		if err := rt.Do(req, resp); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if err := rd.follow(rt, req, resp, hop); err != expectedErr {
+			t.Fatalf("Unexpected error of %q. Got: %v; Expected: %v", path, err, expectedErr)
+		}
+		if len(rt.hops) != hops {
+			t.Errorf("Unexpected number of hops of %q. Got: %v; Expected: %d", path, rt.hops, hops)
+		}
+	}
+
+	f("/a", 3, nil)
+	if last := rt.hops[len(rt.hops)-1]; last != "http://localhost/c?d=1" {
+		t.Errorf("Unexpected last hop. Got: %q; Expected: %q", last, "http://localhost/c?d=1")
+	}
+	f("/loop", 3, errTooManyRedirects)
+
+	// cross-host redirects aren't followed by default
+	f("/cross", 1, nil)
+	*redirectCrossHost = true
+	defer func() { *redirectCrossHost = false }()
+	f("/cross", 1, nil)
+	if len(other.hops) != 1 || other.hops[0] != "http://example.com/final" {
+		t.Errorf("Unexpected hops of other host. Got: %v; Expected: %v", other.hops, []string{"http://example.com/final"})
+	}
+}
@@ -0,0 +1,136 @@
+package fastclient
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+var (
+	dnsRotate   = flag.Bool("dnsRotate", false, "Spread connections across all resolved IPs of host instead of using the first one")
+	dnsCacheTTL = flag.Duration("dnsCacheTTL", time.Minute, "How long resolved IPs are cached. Every new connection resolves host if set to 0")
+)
+
+var overrides = make(resolveOverrides)
+
+func init() {
+	flag.Var(&overrides, "resolve", "Resolve host:port to given IPs instead of DNS lookup, like curl's --resolve. "+
+		"Format is host:port:ip[,ip...]. Could be passed multiple times")
+}
+
+// resolveOverrides maps host:port to list of IPs
+type resolveOverrides map[string][]string
+
+// String implements flag.Value
+func (ro *resolveOverrides) String() string {
+	var s []string
+	for k, ips := range *ro {
+		s = append(s, k+":"+strings.Join(ips, ","))
+	}
+	return strings.Join(s, " ")
+}
+
+// Set implements flag.Value
+func (ro *resolveOverrides) Set(value string) error {
+	n := strings.Index(value, ":")
+	if n < 0 {
+		return fmt.Errorf("cannot parse %q; expected format is host:port:ip", value)
+	}
+	m := strings.Index(value[n+1:], ":")
+	if m < 0 {
+		return fmt.Errorf("cannot parse %q; expected format is host:port:ip", value)
+	}
+	key, list := value[:n+1+m], value[n+2+m:]
+	for _, ip := range strings.Split(list, ",") {
+		ip = strings.Trim(strings.TrimSpace(ip), "[]")
+		if net.ParseIP(ip) == nil {
+			return fmt.Errorf("cannot parse IP %q in %q", ip, value)
+		}
+		(*ro)[key] = append((*ro)[key], ip)
+	}
+	return nil
+}
+
+type dnsEntry struct {
+	ips     []string
+	expires time.Time
+}
+
+var (
+	dnsCacheMu sync.Mutex
+	dnsCache   = make(map[string]dnsEntry)
+
+	// dnsNext is used for rotation across resolved IPs
+	dnsNext uint32
+)
+
+// resolve returns IP for connection to host:port.
+// IP is taken from -resolve overrides, DNS cache or DNS lookup.
+// IPv4 addresses are preferred unless -dnsRotate is set.
+// DNS phase is observed for every connection, so IPs taken
+// without lookup are counted as nearly zero samples
+func resolve(host, port string, deadline time.Time) (string, error) {
+	s := time.Now()
+	if net.ParseIP(host) != nil {
+		dnsDuration.Observe(time.Since(s).Seconds())
+		return host, nil
+	}
+
+	ips, ok := overrides[net.JoinHostPort(host, port)]
+	if !ok {
+		var err error
+		if ips, err = lookup(host, deadline); err != nil {
+			return "", err
+		}
+	}
+	dnsDuration.Observe(time.Since(s).Seconds())
+	if *dnsRotate {
+		return ips[int(atomic.AddUint32(&dnsNext, 1)%uint32(len(ips)))], nil
+	}
+	for _, ip := range ips {
+		if strings.Contains(ip, ".") {
+			return ip, nil
+		}
+	}
+	return ips[0], nil
+}
+
+func lookup(host string, deadline time.Time) ([]string, error) {
+	dnsCacheMu.Lock()
+	e, ok := dnsCache[host]
+	dnsCacheMu.Unlock()
+	if ok && time.Now().Before(e.expires) {
+		return e.ips, nil
+	}
+
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fasthttp.ErrDialTimeout
+		}
+		return nil, err
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no addresses found for %q", host)
+	}
+
+	e = dnsEntry{expires: time.Now().Add(*dnsCacheTTL)}
+	for _, a := range addrs {
+		e.ips = append(e.ips, a.IP.String())
+	}
+	if *dnsCacheTTL > 0 {
+		dnsCacheMu.Lock()
+		dnsCache[host] = e
+		dnsCacheMu.Unlock()
+	}
+	return e.ips, nil
+}
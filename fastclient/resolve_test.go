@@ -0,0 +1,76 @@
+package fastclient
+
+import (
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestResolveOverrides(t *testing.T) {
+	ro := make(resolveOverrides)
+	if err := ro.Set("example.com:443:127.0.0.1,[::1]"); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	ips := ro["example.com:443"]
+	if len(ips) != 2 || ips[0] != "127.0.0.1" || ips[1] != "::1" {
+		t.Fatalf("Unexpected IPs. Got: %v; Expected: %v", ips, []string{"127.0.0.1", "::1"})
+	}
+	for _, v := range []string{"example.com", "example.com:443", "example.com:443:localhost"} {
+		if err := ro.Set(v); err == nil {
+			t.Errorf("Expected error for %q", v)
+		}
+	}
+}
+
+func TestResolveRotate(t *testing.T) {
+	overrides["example.com:80"] = []string{"::1", "127.0.0.1", "127.0.0.2"}
+	defer delete(overrides, "example.com:80")
+
+	deadline := time.Now().Add(time.Second)
+	if ip, _ := resolve("example.com", "80", deadline); ip != "127.0.0.1" {
+		t.Errorf("Expected IPv4 address to be preferred. Got: %q", ip)
+	}
+
+	*dnsRotate = true
+	defer func() { *dnsRotate = false }()
+	seen := make(map[string]bool)
+	for i := 0; i < 3; i++ {
+		ip, err := resolve("example.com", "80", deadline)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		seen[ip] = true
+	}
+	if len(seen) != 3 {
+		t.Errorf("Expected all IPs to be used. Got: %v", seen)
+	}
+}
+
+func TestResolveDNSDuration(t *testing.T) {
+	dnsCacheMu.Lock()
+	dnsCache["example.com"] = dnsEntry{ips: []string{"127.0.0.1"}, expires: time.Now().Add(time.Minute)}
+	dnsCacheMu.Unlock()
+	defer func() {
+		dnsCacheMu.Lock()
+		delete(dnsCache, "example.com")
+		dnsCacheMu.Unlock()
+	}()
+
+	// every connection is observed, even if IP is taken from cache
+	before := dnsSamples()
+	for i := 0; i < 3; i++ {
+		if _, err := resolve("example.com", "80", time.Now().Add(time.Second)); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	}
+	if n := dnsSamples() - before; n != 3 {
+		t.Errorf("Unexpected number of DNS samples. Got: %d; Expected: %d", n, 3)
+	}
+}
+
+func dnsSamples() uint64 {
+	var m dto.Metric
+	dnsDuration.Write(&m)
+	return m.GetSummary().GetSampleCount()
+}
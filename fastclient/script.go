@@ -0,0 +1,160 @@
+package fastclient
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/dop251/goja"
+	"github.com/valyala/fasthttp"
+)
+
+var scriptFile = flag.String("script", "", "JavaScript file with request(req) and response(req, resp) functions, "+
+	"which are called by every worker before sending request and after receiving response. "+
+	"Exception thrown by script fails the request and is counted as script error")
+
+// script is a compiled JavaScript program
+// which is shared between workers
+type script struct {
+	program *goja.Program
+}
+
+// loadScript compiles script from file and checks that it could be run
+// or returns nil if path is empty
+func loadScript(path string) (*script, error) {
+	if path == "" {
+		return nil, nil
+	}
+	src, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	p, err := goja.Compile(path, string(src), false)
+	if err != nil {
+		return nil, fmt.Errorf("cannot compile script %q: %s", path, err)
+	}
+	s := &script{program: p}
+	sw, err := newScriptWorker(s, nil)
+	if err != nil {
+		return nil, err
+	}
+	if sw.request == nil && sw.response == nil {
+		return nil, fmt.Errorf("script %q defines neither request nor response function", path)
+	}
+	return s, nil
+}
+
+// scriptWorker is a Transport which calls script hooks
+// of worker around requests. Every worker has own JavaScript
+// runtime, so global variables of script are kept per worker
+type scriptWorker struct {
+	t  Transport
+	rt *goja.Runtime
+
+	request  goja.Callable
+	response goja.Callable
+
+	// req and resp are objects passed to hooks,
+	// which give access to currently processed request and response
+	req, resp *goja.Object
+	curReq    *fasthttp.Request
+	curResp   *assertedResponse
+}
+
+func newScriptWorker(s *script, t Transport) (*scriptWorker, error) {
+	sw := &scriptWorker{
+		t:  t,
+		rt: goja.New(),
+	}
+	sw.rt.Set("hmacSHA256", func(key, data string) string {
+		return hex.EncodeToString(hmacSHA256([]byte(key), data))
+	})
+	sw.rt.Set("sha256", func(data string) string {
+		h := sha256.Sum256([]byte(data))
+		return hex.EncodeToString(h[:])
+	})
+	sw.rt.Set("base64", func(data string) string {
+		return base64.StdEncoding.EncodeToString([]byte(data))
+	})
+	if _, err := sw.rt.RunProgram(s.program); err != nil {
+		return nil, fmt.Errorf("cannot run script: %s", err)
+	}
+	sw.request, _ = goja.AssertFunction(sw.rt.Get("request"))
+	sw.response, _ = goja.AssertFunction(sw.rt.Get("response"))
+
+	sw.req = sw.rt.NewObject()
+	sw.req.Set("method", func() string { return string(sw.curReq.Header.Method()) })
+	sw.req.Set("setMethod", func(m string) { sw.curReq.Header.SetMethod(m) })
+	sw.req.Set("uri", func() string { return string(sw.curReq.URI().RequestURI()) })
+	sw.req.Set("setUri", func(uri string) { sw.curReq.URI().Update(uri) })
+	sw.req.Set("header", func(name string) string { return string(sw.curReq.Header.Peek(name)) })
+	sw.req.Set("setHeader", func(name, value string) { sw.curReq.Header.Set(name, value) })
+	sw.req.Set("delHeader", func(name string) { sw.curReq.Header.Del(name) })
+	sw.req.Set("body", func() string { return string(sw.curReq.Body()) })
+	sw.req.Set("setBody", func(body string) { sw.curReq.SetBodyString(body) })
+
+	sw.resp = sw.rt.NewObject()
+	sw.resp.Set("status", func() int { return sw.curResp.resp.StatusCode() })
+	sw.resp.Set("header", func(name string) string { return string(sw.curResp.resp.Header.Peek(name)) })
+	sw.resp.Set("body", func() string { return string(sw.curResp.getBody()) })
+	return sw, nil
+}
+
+// Do calls request hook, sends req and calls response hook
+func (sw *scriptWorker) Do(req *fasthttp.Request, resp *fasthttp.Response) error {
+	sw.curReq = req
+	if sw.request != nil {
+		if err := sw.call(sw.request, sw.req); err != nil {
+			resp.Reset()
+			return err
+		}
+	}
+	if err := sw.t.Do(req, resp); err != nil {
+		return err
+	}
+	if sw.response != nil {
+		sw.curResp = &assertedResponse{resp: resp}
+		return sw.call(sw.response, sw.req, sw.resp)
+	}
+	return nil
+}
+
+// call calls hook and counts its errors.
+// Panics are recovered, so broken script doesn't crash worker
+func (sw *scriptWorker) call(fn goja.Callable, args ...*goja.Object) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("script: %v", r)
+		}
+		if err != nil {
+			scriptErrors.Inc()
+		}
+	}()
+	values := make([]goja.Value, len(args))
+	for i, a := range args {
+		values[i] = a
+	}
+	if _, err := fn(goja.Undefined(), values...); err != nil {
+		if ex, ok := err.(*goja.Exception); ok {
+			// stack trace is omitted to keep errors distribution readable
+			return fmt.Errorf("script: %s", ex.Value())
+		}
+		return fmt.Errorf("script: %s", err)
+	}
+	return nil
+}
+
+// scriptFailure is a Transport of worker, which script
+// couldn't be run for. Every request of worker fails
+type scriptFailure struct {
+	err error
+}
+
+func (sf *scriptFailure) Do(req *fasthttp.Request, resp *fasthttp.Response) error {
+	resp.Reset()
+	scriptErrors.Inc()
+	return sf.err
+}
@@ -0,0 +1,149 @@
+package fastclient
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+// echoTransport responds with signature header of request
+type echoTransport struct{}
+
+func (echoTransport) Do(req *fasthttp.Request, resp *fasthttp.Response) error {
+	resp.Reset()
+	resp.Header.Set("X-Echo", string(req.Header.Peek("X-Signature")))
+	resp.SetBodyString(string(req.URI().QueryArgs().Peek("n")))
+	return nil
+}
+
+const testScript = `
+var n = 0;
+function request(req) {
+	n++;
+	req.setUri("/sign?n=" + n);
+	req.setHeader("X-Signature", hmacSHA256("secret", req.method() + " " + req.uri()));
+	if (n == 3) {
+		throw "broken request";
+	}
+}
+function response(req, resp) {
+	if (resp.header("X-Echo") !== req.header("X-Signature")) {
+		throw "signature mismatch";
+	}
+	if (resp.body() == "4") {
+		throw "unexpected body " + resp.body();
+	}
+}
+`
+
+func writeTempFile(t *testing.T, data string) string {
+	f, err := ioutil.TempFile("", "fastclient")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	f.WriteString(data)
+	f.Close()
+	return f.Name()
+}
+
+func TestScript(t *testing.T) {
+	path := writeTempFile(t, testScript)
+	defer os.Remove(path)
+	s, err := loadScript(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	sw, err := newScriptWorker(s, echoTransport{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	req := new(fasthttp.Request)
+	req.SetRequestURI("http://localhost/")
+	resp := new(fasthttp.Response)
+	before := (*Client)(nil).ScriptErrors()
+	expected := []string{"", "", "script: broken request", "script: unexpected body 4", ""}
+	for i, e := range expected {
+		err := sw.Do(req, resp)
+		if (err == nil && e != "") || (err != nil && err.Error() != e) {
+			t.Errorf("Unexpected error of request %d. Got: %v; Expected: %q", i, err, e)
+		}
+	}
+	if n := (*Client)(nil).ScriptErrors() - before; n != 2 {
+		t.Errorf("Unexpected number of script errors. Got: %d; Expected: %d", n, 2)
+	}
+	if host := string(req.URI().Host()); host != "localhost" {
+		t.Errorf("Unexpected host. Got: %q; Expected: %q", host, "localhost")
+	}
+	if sig := string(req.Header.Peek("X-Signature")); len(sig) != 64 {
+		t.Errorf("Unexpected signature %q", sig)
+	}
+
+	for _, src := range []string{"function request(req) {", "var x = 1;", "throw 'init';"} {
+		path := writeTempFile(t, src)
+		if _, err := loadScript(path); err == nil {
+			t.Errorf("Expected error for script %q", src)
+		}
+		os.Remove(path)
+	}
+}
+
+// bodyTransport fails requests with body other than expected
+type bodyTransport struct {
+	body string
+}
+
+func (bt bodyTransport) Do(req *fasthttp.Request, resp *fasthttp.Response) error {
+	resp.Reset()
+	if string(req.Body()) != bt.body {
+		return fmt.Errorf("unexpected body %q", req.Body())
+	}
+	return nil
+}
+
+func TestClientScript(t *testing.T) {
+	path := writeTempFile(t, `
+var n = 0;
+function request(req) {
+	n++;
+	req.setBody(req.body() + "x");
+	if (n > 5) {
+		throw "broken request";
+	}
+}`)
+	defer os.Remove(path)
+	*scriptFile = path
+	defer func() { *scriptFile = "" }()
+
+	req := new(fasthttp.Request)
+	req.SetRequestURI("http://localhost/")
+	req.SetBodyString("a")
+	c := NewWithTransport(req, bodyTransport{body: "ax"}, "200")
+	c.RunWorkers(1)
+	defer c.Flush()
+	for i := 0; i < 10; i++ {
+		c.Jobsch <- struct{}{}
+	}
+	waitRequests(t, c, 10)
+
+	// changes of script aren't accumulated between requests,
+	// while failed hooks are counted as errors
+	if c.RequestSuccess() != 5 {
+		t.Errorf("Unexpected number of successful requests. Got: %d; Expected: %d", c.RequestSuccess(), 5)
+	}
+	if n := c.ErrorMessages()["script: broken request"]; n != 5 {
+		t.Errorf("Unexpected number of script errors. Got: %d; Expected: %d", n, 5)
+	}
+
+	sf := &scriptFailure{err: fmt.Errorf("cannot run script")}
+	before := c.ScriptErrors()
+	if err := sf.Do(req, new(fasthttp.Response)); err != sf.err {
+		t.Errorf("Unexpected error. Got: %v; Expected: %v", err, sf.err)
+	}
+	if n := c.ScriptErrors() - before; n != 1 {
+		t.Errorf("Unexpected number of script errors. Got: %d; Expected: %d", n, 1)
+	}
+}
@@ -0,0 +1,167 @@
+package fastclient
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+var (
+	cookies = flag.Bool("cookies", false, "Keep cookie jar per worker, so every worker acts as a virtual user with own session. "+
+		"Enabled automatically if -loginUrl or -sessionRequests is set")
+	loginURL = flag.String("loginUrl", "", "Url of request which is sent by every worker before the first request of session, e.g. /login. "+
+		"It must point to the same host as tested url")
+	loginMethod      = flag.String("loginMethod", "POST", "Method of login request")
+	loginBody        = flag.String("loginBody", "", "Body of login request, e.g. user=test&password=secret")
+	loginContentType = flag.String("loginContentType", "application/x-www-form-urlencoded", "Content-Type of login request")
+	sessionRequests  = flag.Int("sessionRequests", 0, "Reset session of worker every N requests: cookies are dropped and login request is sent again. "+
+		"Session is never reset if 0")
+)
+
+// sessionsEnabled returns true if workers should keep sessions
+func sessionsEnabled() bool {
+	return *cookies || *loginURL != "" || *sessionRequests > 0
+}
+
+// newLoginRequest returns request for login based on req
+// or nil if -loginUrl isn't set
+func newLoginRequest(req *fasthttp.Request) (*fasthttp.Request, error) {
+	if *loginURL == "" {
+		return nil, nil
+	}
+	lr := new(fasthttp.Request)
+	req.CopyTo(lr)
+	lr.URI().Update(*loginURL)
+	if string(lr.URI().Host()) != string(req.URI().Host()) || string(lr.URI().Scheme()) != string(req.URI().Scheme()) {
+		return nil, fmt.Errorf("loginUrl %q must point to the same host as tested url", *loginURL)
+	}
+	lr.Header.SetMethod(*loginMethod)
+	lr.SetBodyString(*loginBody)
+	if *loginBody != "" {
+		lr.Header.SetContentType(*loginContentType)
+	}
+	return lr, nil
+}
+
+// session is a Transport of virtual user.
+// It keeps cookies set by responses, sends them with
+// further requests and logs in at the start of session
+type session struct {
+	t     Transport
+	login *fasthttp.Request
+
+	// base contains cookies of original request
+	base [][2][]byte
+
+	// jar contains cookies by name
+	jar      map[string]*fasthttp.Cookie
+	loggedIn bool
+	requests int
+
+	loginResp fasthttp.Response
+}
+
+func newSession(t Transport, req, login *fasthttp.Request) *session {
+	s := &session{
+		t:   t,
+		jar: make(map[string]*fasthttp.Cookie),
+	}
+	req.Header.VisitAllCookie(func(key, value []byte) {
+		s.base = append(s.base, [2][]byte{append([]byte{}, key...), append([]byte{}, value...)})
+	})
+	if login != nil {
+		s.login = new(fasthttp.Request)
+		login.CopyTo(s.login)
+	}
+	return s
+}
+
+// Do sends request with session cookies.
+// Session is reset after every -sessionRequests requests
+func (s *session) Do(req *fasthttp.Request, resp *fasthttp.Response) error {
+	if s.login != nil && !s.loggedIn {
+		if err := s.doLogin(); err != nil {
+			return err
+		}
+	}
+
+	s.setCookies(req)
+	err := s.t.Do(req, resp)
+	s.storeCookies(resp)
+
+	s.requests++
+	if *sessionRequests > 0 && s.requests%*sessionRequests == 0 {
+		s.reset()
+	}
+	return err
+}
+
+func (s *session) doLogin() error {
+	s.setCookies(s.login)
+	err := s.t.Do(s.login, &s.loginResp)
+	s.storeCookies(&s.loginResp)
+	logins.Inc()
+	if err == nil && s.loginResp.StatusCode() >= fasthttp.StatusBadRequest {
+		err = fmt.Errorf("unexpected status code %d", s.loginResp.StatusCode())
+	}
+	if err != nil {
+		loginErrors.Inc()
+		return fmt.Errorf("login failed: %s", err)
+	}
+	s.loggedIn = true
+	return nil
+}
+
+// reset drops cookies of session, so next request starts new session
+func (s *session) reset() {
+	for k, c := range s.jar {
+		fasthttp.ReleaseCookie(c)
+		delete(s.jar, k)
+	}
+	s.loggedIn = false
+	sessionResets.Inc()
+}
+
+// setCookies replaces cookies of req with cookies of original request and session
+func (s *session) setCookies(req *fasthttp.Request) {
+	req.Header.DelAllCookies()
+	for _, kv := range s.base {
+		req.Header.SetCookieBytesKV(kv[0], kv[1])
+	}
+	now := time.Now()
+	for name, c := range s.jar {
+		if exp := c.Expire(); exp != fasthttp.CookieExpireUnlimited && exp.Before(now) {
+			fasthttp.ReleaseCookie(c)
+			delete(s.jar, name)
+			continue
+		}
+		req.Header.SetCookieBytesKV(c.Key(), c.Value())
+	}
+}
+
+func (s *session) storeCookies(resp *fasthttp.Response) {
+	resp.Header.VisitAllCookie(func(key, value []byte) {
+		c := fasthttp.AcquireCookie()
+		if err := c.ParseBytes(value); err != nil {
+			fasthttp.ReleaseCookie(c)
+			return
+		}
+		name := string(c.Key())
+		if old, ok := s.jar[name]; ok {
+			fasthttp.ReleaseCookie(old)
+			delete(s.jar, name)
+		}
+		// negative Max-Age, empty value or expiration in the past mean cookie deletion
+		if c.MaxAge() < 0 || len(c.Value()) == 0 ||
+			(c.Expire() != fasthttp.CookieExpireUnlimited && c.Expire().Before(time.Now())) {
+			fasthttp.ReleaseCookie(c)
+			return
+		}
+		if c.MaxAge() > 0 {
+			c.SetExpire(time.Now().Add(time.Duration(c.MaxAge()) * time.Second))
+		}
+		s.jar[name] = c
+	})
+}
@@ -0,0 +1,65 @@
+package fastclient
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+// sessionTransport issues session cookie on login
+// and records cookies of other requests
+type sessionTransport struct {
+	logins  int
+	cookies []string
+}
+
+func (st *sessionTransport) Do(req *fasthttp.Request, resp *fasthttp.Response) error {
+	resp.Reset()
+	if string(req.URI().Path()) == "/login" {
+		st.logins++
+		resp.Header.Set("Set-Cookie", "sid=s"+strconv.Itoa(st.logins)+"; Path=/")
+		return nil
+	}
+	st.cookies = append(st.cookies, string(req.Header.Peek("Cookie")))
+	return nil
+}
+
+func TestSession(t *testing.T) {
+	*loginURL, *sessionRequests = "/login", 2
+	defer func() { *loginURL, *sessionRequests = "", 0 }()
+
+	req := new(fasthttp.Request)
+	req.SetRequestURI("http://localhost/profile")
+	req.Header.SetCookie("lang", "en")
+	login, err := newLoginRequest(req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if uri := login.URI().String(); uri != "http://localhost/login" {
+		t.Fatalf("Unexpected login uri. Got: %q; Expected: %q", uri, "http://localhost/login")
+	}
+
+	st := &sessionTransport{}
+	s := newSession(st, req, login)
+	resp := new(fasthttp.Response)
+	for i := 0; i < 4; i++ {
+		if err := s.Do(req, resp); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	}
+	if st.logins != 2 {
+		t.Errorf("Unexpected number of logins. Got: %d; Expected: %d", st.logins, 2)
+	}
+	expected := []string{"lang=en; sid=s1", "lang=en; sid=s1", "lang=en; sid=s2", "lang=en; sid=s2"}
+	for i, c := range st.cookies {
+		if c != expected[i] {
+			t.Errorf("Unexpected cookies of request %d. Got: %q; Expected: %q", i, c, expected[i])
+		}
+	}
+
+	*loginURL = "http://example.com/login"
+	if _, err := newLoginRequest(req); err == nil {
+		t.Errorf("Expected error for login url of other host")
+	}
+}
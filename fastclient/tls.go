@@ -0,0 +1,132 @@
+package fastclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"strings"
+	"time"
+)
+
+var (
+	tlsCA         = flag.String("tlsCA", "", "File with PEM-encoded CA certificates to verify server certificate. System pool is used if not set")
+	tlsCert       = flag.String("tlsCert", "", "File with PEM-encoded client certificate for mTLS")
+	tlsKey        = flag.String("tlsKey", "", "File with PEM-encoded private key of client certificate")
+	tlsServerName = flag.String("tlsServerName", "", "Server name for SNI and certificate verification. Host of url is used if not set")
+	tlsMinVersion = flag.String("tlsMinVersion", "", "Minimum TLS version: 1.0, 1.1, 1.2 or 1.3")
+	tlsMaxVersion = flag.String("tlsMaxVersion", "", "Maximum TLS version: 1.0, 1.1, 1.2 or 1.3. Set to 1.2 to make -tlsCiphers take effect")
+	tlsCiphers    = flag.String("tlsCiphers", "", "Comma-separated list of allowed cipher suites for TLS 1.0-1.2, e.g. TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256")
+	tlsInsecure   = flag.Bool("tlsInsecure", false, "Skip verification of server certificate")
+	tlsResume     = flag.Bool("tlsResume", false, "Resume TLS sessions, so new connections perform abbreviated handshake. "+
+		"Every new connection performs full handshake by default, which stresses server's crypto along with -k")
+)
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// newTLSConfig builds tls.Config from tls* flags
+func newTLSConfig() (*tls.Config, error) {
+	cfg := &tls.Config{
+		ServerName:         *tlsServerName,
+		InsecureSkipVerify: *tlsInsecure,
+	}
+	if *tlsResume {
+		cfg.ClientSessionCache = tls.NewLRUClientSessionCache(0)
+	}
+
+	if *tlsCA != "" {
+		data, err := ioutil.ReadFile(*tlsCA)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read CA file: %s", err)
+		}
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificates found in CA file %q", *tlsCA)
+		}
+	}
+
+	if *tlsCert != "" || *tlsKey != "" {
+		cert, err := tls.LoadX509KeyPair(*tlsCert, *tlsKey)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load client certificate: %s", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	var err error
+	if cfg.MinVersion, err = parseTLSVersion(*tlsMinVersion); err != nil {
+		return nil, err
+	}
+	if cfg.MaxVersion, err = parseTLSVersion(*tlsMaxVersion); err != nil {
+		return nil, err
+	}
+	if cfg.MinVersion != 0 && cfg.MaxVersion != 0 && cfg.MinVersion > cfg.MaxVersion {
+		return nil, fmt.Errorf("-tlsMinVersion %s is greater than -tlsMaxVersion %s", *tlsMinVersion, *tlsMaxVersion)
+	}
+
+	if *tlsCiphers != "" {
+		suites := make(map[string]uint16)
+		for _, s := range append(tls.CipherSuites(), tls.InsecureCipherSuites()...) {
+			suites[s.Name] = s.ID
+		}
+		for _, name := range strings.Split(*tlsCiphers, ",") {
+			id, ok := suites[strings.TrimSpace(name)]
+			if !ok {
+				return nil, fmt.Errorf("unsupported cipher suite %q", name)
+			}
+			cfg.CipherSuites = append(cfg.CipherSuites, id)
+		}
+	}
+	return cfg, nil
+}
+
+// parseTLSVersion returns TLS version by its name or 0 if name is empty
+func parseTLSVersion(name string) (uint16, error) {
+	if name == "" {
+		return 0, nil
+	}
+	v, ok := tlsVersions[name]
+	if !ok {
+		return 0, fmt.Errorf("unsupported TLS version %q; supported versions are 1.0, 1.1, 1.2 and 1.3", name)
+	}
+	return v, nil
+}
+
+// dialTLS establishes connection and performs TLS handshake,
+// so handshake failures are counted separately from other errors
+func dialTLS(addr string, cfg *tls.Config, timeout time.Duration) (net.Conn, error) {
+	conn, err := dial(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.ServerName == "" {
+		cfg = cfg.Clone()
+		cfg.ServerName = addr
+		if host, _, err := net.SplitHostPort(addr); err == nil {
+			cfg.ServerName = host
+		}
+	}
+	tc := tls.Client(conn, cfg)
+	s := time.Now()
+	tc.SetDeadline(s.Add(timeout))
+	if err := tc.Handshake(); err != nil {
+		tlsHandshakeErrors.Inc()
+		conn.Close()
+		return nil, fmt.Errorf("tls handshake: %s", err)
+	}
+	tc.SetDeadline(time.Time{})
+	tlsHandshakeDuration.Observe(time.Since(s).Seconds())
+	tlsHandshakes.Inc()
+	if tc.ConnectionState().DidResume {
+		tlsResumedHandshakes.Inc()
+	}
+	return tc, nil
+}
@@ -0,0 +1,114 @@
+package fastclient
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+)
+
+// writeTestCert writes self-signed certificate and its key
+// to temporary files and returns their paths
+func writeTestCert(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	tpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "fasthttploader"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tpl, tpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	cert := writeTempFile(t, string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})))
+	keyFile := writeTempFile(t, string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})))
+	return cert, keyFile
+}
+
+func TestTLSConfig(t *testing.T) {
+	cert, key := writeTestCert(t)
+	defer os.Remove(cert)
+	defer os.Remove(key)
+	defer func() {
+		*tlsCA, *tlsCert, *tlsKey = "", "", ""
+		*tlsMinVersion, *tlsMaxVersion, *tlsCiphers = "", "", ""
+	}()
+
+	*tlsCA, *tlsCert, *tlsKey = cert, cert, key
+	*tlsMinVersion, *tlsMaxVersion = "1.1", "1.2"
+	*tlsCiphers = "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256, TLS_RSA_WITH_AES_128_CBC_SHA"
+	cfg, err := newTLSConfig()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if cfg.RootCAs == nil {
+		t.Errorf("Expected CA pool to be set")
+	}
+	if len(cfg.Certificates) != 1 {
+		t.Errorf("Unexpected number of client certificates. Got: %d; Expected: %d", len(cfg.Certificates), 1)
+	}
+	if cfg.MinVersion != tls.VersionTLS11 || cfg.MaxVersion != tls.VersionTLS12 {
+		t.Errorf("Unexpected versions. Got: %x-%x; Expected: %x-%x", cfg.MinVersion, cfg.MaxVersion, tls.VersionTLS11, tls.VersionTLS12)
+	}
+	expected := []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256, tls.TLS_RSA_WITH_AES_128_CBC_SHA}
+	if len(cfg.CipherSuites) != 2 || cfg.CipherSuites[0] != expected[0] || cfg.CipherSuites[1] != expected[1] {
+		t.Errorf("Unexpected cipher suites. Got: %x; Expected: %x", cfg.CipherSuites, expected)
+	}
+
+	testCases := []struct {
+		name  string
+		apply func()
+	}{
+		{"missing CA", func() { *tlsCA = cert + ".missing" }},
+		{"CA without certificates", func() { *tlsCA = key }},
+		{"cert without key", func() { *tlsKey = "" }},
+		{"key without cert", func() { *tlsCert = "" }},
+		{"wrong key", func() { *tlsKey = cert }},
+		{"unknown min version", func() { *tlsMinVersion = "1.4" }},
+		{"unknown max version", func() { *tlsMaxVersion = "TLS1.2" }},
+		{"min version above max", func() { *tlsMinVersion, *tlsMaxVersion = "1.3", "1.2" }},
+		{"unknown cipher", func() { *tlsCiphers = "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,TLS_FOO" }},
+	}
+	for _, tc := range testCases {
+		*tlsCA, *tlsCert, *tlsKey = cert, cert, key
+		*tlsMinVersion, *tlsMaxVersion, *tlsCiphers = "", "", ""
+		tc.apply()
+		if _, err := newTLSConfig(); err == nil {
+			t.Errorf("%s: expected error", tc.name)
+		}
+	}
+}
+
+func TestTLSResume(t *testing.T) {
+	cfg, err := newTLSConfig()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if cfg.ClientSessionCache != nil {
+		t.Errorf("Expected sessions not to be resumed by default")
+	}
+
+	*tlsResume = true
+	defer func() { *tlsResume = false }()
+	if cfg, err = newTLSConfig(); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if cfg.ClientSessionCache == nil {
+		t.Errorf("Expected session cache to be set with -tlsResume")
+	}
+}
@@ -0,0 +1,29 @@
+package fastclient
+
+import (
+	"github.com/valyala/fasthttp"
+)
+
+// Transport sends request and fills response.
+// It is used by Client workers concurrently, so must be thread-safe.
+// fasthttp.HostClient is an example of HTTP/1.1 Transport
+type Transport interface {
+	Do(req *fasthttp.Request, resp *fasthttp.Response) error
+}
+
+// WorkerTransport is a Transport which needs own state per worker,
+// e.g. persistent connection. Client calls Worker on every worker start
+// and uses returned Transport by that worker only.
+// Returned Transport is closed on worker exit if it implements io.Closer
+type WorkerTransport interface {
+	Transport
+	Worker() Transport
+}
+
+// Statuser may be implemented by Transport, which responses carry
+// own status codes instead of HTTP ones, e.g. gRPC.
+// Status returns code, which is compared with success status code,
+// and label to display in report
+type Statuser interface {
+	Status(resp *fasthttp.Response) (code int, label string)
+}
@@ -0,0 +1,108 @@
+package fastclient
+
+import (
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestAcquireAddr(t *testing.T) {
+	testCases := []struct {
+		url    string
+		host   string
+		socket string
+		addr   string
+		isTLS  bool
+		uri    string
+		err    string
+	}{
+		{url: "http://example.com/a", addr: "example.com:80", uri: "http://example.com/a"},
+		{url: "https://example.com/a", addr: "example.com:443", isTLS: true, uri: "https://example.com/a"},
+		{url: "http://example.com:8080/a", addr: "example.com:8080", uri: "http://example.com:8080/a"},
+		{url: "http://example.com:port/a", err: "cannot parse port"},
+		{url: "http://example.com/a", socket: "/tmp/sock", addr: "unix:/tmp/sock", uri: "http://example.com/a"},
+		{url: "unix:/tmp/sock", addr: "unix:/tmp/sock", uri: "http://localhost/"},
+		{url: "unix:/tmp/sock:/a/b?c=1", addr: "unix:/tmp/sock", uri: "http://localhost/a/b?c=1"},
+		{url: "unix:/tmp/sock:/a", host: "example.com", addr: "unix:/tmp/sock", uri: "http://example.com/a"},
+		{url: "unix:", err: "socket path cannot be empty"},
+		{url: "unix:/tmp/sock:a", err: "must start with /"},
+		{url: "unix:/tmp/sock", socket: "/tmp/sock", err: "can't be used along with"},
+	}
+	defer func() { *unixSocket = "" }()
+	for _, tc := range testCases {
+		*unixSocket = tc.socket
+		req := new(fasthttp.Request)
+		if tc.host != "" {
+			req.Header.Set("Host", tc.host)
+		}
+		req.Header.SetRequestURI(tc.url)
+		addr, isTLS, err := acquireAddr(req)
+		if tc.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("%s: unexpected error. Got: %v; Expected: %q", tc.url, err, tc.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", tc.url, err)
+		}
+		if addr != tc.addr || isTLS != tc.isTLS {
+			t.Errorf("%s: unexpected address. Got: %q %v; Expected: %q %v", tc.url, addr, isTLS, tc.addr, tc.isTLS)
+		}
+		if uri := req.URI().String(); uri != tc.uri {
+			t.Errorf("%s: unexpected uri. Got: %q; Expected: %q", tc.url, uri, tc.uri)
+		}
+	}
+}
+
+func TestUnixSocket(t *testing.T) {
+	*maxRedirects = 1
+	defer func() { *maxRedirects = 0 }()
+	sock := filepath.Join(t.TempDir(), "sock")
+	ln, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	var mu sync.Mutex
+	var received []string
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		received = append(received, r.Host+r.RequestURI)
+		mu.Unlock()
+		if r.URL.Path == "/redirect" {
+			// same host redirect is sent over socket as well
+			http.Redirect(w, r, "/final", http.StatusFound)
+		}
+	})}
+	go srv.Serve(ln)
+	defer srv.Close()
+
+	req := new(fasthttp.Request)
+	req.Header.Set("Host", "example.com")
+	req.Header.SetRequestURI("unix:" + sock + ":/redirect?a=1")
+	c, err := New(req, time.Second, "200")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	c.RunWorkers(1)
+	defer c.Flush()
+	defer c.transport.(*fasthttp.HostClient).CloseIdleConnections()
+	c.Jobsch <- struct{}{}
+	waitRequests(t, c, 1)
+
+	if c.Errors() != 0 {
+		t.Fatalf("Unexpected errors: %v", c.ErrorMessages())
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	expected := []string{"example.com/redirect?a=1", "example.com/final"}
+	if strings.Join(received, " ") != strings.Join(expected, " ") {
+		t.Errorf("Unexpected requests. Got: %q; Expected: %q", received, expected)
+	}
+}
@@ -0,0 +1,224 @@
+package fastclient
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	stderrors "errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"text/template"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/valyala/fasthttp"
+)
+
+var (
+	wsMessages = flag.String("wsMessages", "", "File with WebSocket message templates, one per line. Messages are sent by turn. "+
+		"Templates may contain {{.Worker}}, {{.Seq}} and {{.Timestamp}}. Request body is sent if not set")
+	wsReply = flag.Bool("wsReply", true, "Wait for reply on every WebSocket message to measure round-trip latency")
+)
+
+// isWebsocket returns true if request should be sent over WebSocket
+func isWebsocket(req *fasthttp.Request) bool {
+	scheme := string(req.URI().Scheme())
+	return scheme == "ws" || scheme == "wss"
+}
+
+// wsTransport keeps WebSocket connection per worker
+// and sends a message on every Do
+type wsTransport struct {
+	timeout   time.Duration
+	dialer    *websocket.Dialer
+	templates []*template.Template
+	workers   int32
+}
+
+// wsMessageData is passed to message templates
+type wsMessageData struct {
+	Worker    int32
+	Seq       uint64
+	Timestamp int64
+}
+
+func newWebsocketTransport(req *fasthttp.Request, timeout time.Duration, tlsConfig *tls.Config) (*wsTransport, error) {
+	wt := &wsTransport{
+		timeout: timeout,
+		dialer: &websocket.Dialer{
+			NetDial: func(network, addr string) (net.Conn, error) {
+				return dial(addr)
+			},
+			NetDialTLSContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				return dialTLS(addr, tlsConfig, timeout)
+			},
+			HandshakeTimeout: timeout,
+		},
+	}
+
+	lines := []string{string(req.Body())}
+	if *wsMessages != "" {
+		var err error
+		if lines, err = readLines(*wsMessages); err != nil {
+			return nil, err
+		}
+	}
+	for i, line := range lines {
+		tpl, err := template.New(fmt.Sprintf("message %d", i)).Parse(line)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse WebSocket message template: %s", err)
+		}
+		wt.templates = append(wt.templates, tpl)
+	}
+	return wt, nil
+}
+
+func readLines(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		if line := s.Text(); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("file %q contains no messages", path)
+	}
+	return lines, nil
+}
+
+// Worker returns Transport with own WebSocket connection
+func (wt *wsTransport) Worker() Transport {
+	return &wsWorker{
+		wsTransport: wt,
+		data:        wsMessageData{Worker: atomic.AddInt32(&wt.workers, 1)},
+	}
+}
+
+// Do sends single message over new connection
+func (wt *wsTransport) Do(req *fasthttp.Request, resp *fasthttp.Response) error {
+	w := wt.Worker().(*wsWorker)
+	defer w.Close()
+	return w.Do(req, resp)
+}
+
+type wsWorker struct {
+	*wsTransport
+	conn *websocket.Conn
+	data wsMessageData
+	buf  bytes.Buffer
+}
+
+// Do sends next message and waits for reply if needed.
+// Successful round trip is reported as 200 status code
+func (w *wsWorker) Do(req *fasthttp.Request, resp *fasthttp.Response) error {
+	resp.Reset()
+	if w.conn == nil {
+		if err := w.connect(req, resp); err != nil {
+			return err
+		}
+	}
+
+	w.buf.Reset()
+	tpl := w.templates[w.data.Seq%uint64(len(w.templates))]
+	w.data.Timestamp = time.Now().UnixNano()
+	if err := tpl.Execute(&w.buf, w.data); err != nil {
+		return err
+	}
+	w.data.Seq++
+
+	w.conn.SetWriteDeadline(time.Now().Add(w.timeout))
+	if err := w.conn.WriteMessage(websocket.TextMessage, w.buf.Bytes()); err != nil {
+		return w.disconnect(err)
+	}
+	if *wsReply {
+		w.conn.SetReadDeadline(time.Now().Add(w.timeout))
+		_, data, err := w.conn.ReadMessage()
+		if err != nil {
+			return w.disconnect(err)
+		}
+		resp.SetBody(data)
+	}
+	resp.SetStatusCode(fasthttp.StatusOK)
+	return nil
+}
+
+func (w *wsWorker) connect(req *fasthttp.Request, resp *fasthttp.Response) error {
+	header := make(http.Header)
+	req.Header.VisitAll(func(k, v []byte) {
+		key := http.CanonicalHeaderKey(string(k))
+		if hopHeaders[key] || strings.HasPrefix(key, "Sec-Websocket-") {
+			return
+		}
+		header.Add(key, string(v))
+	})
+
+	s := time.Now()
+	conn, hr, err := w.dialer.Dial(req.URI().String(), header)
+	if err != nil {
+		if hr != nil {
+			resp.SetStatusCode(hr.StatusCode)
+		}
+		return err
+	}
+	wsConnectDuration.Observe(time.Since(s).Seconds())
+	w.conn = conn
+	return nil
+}
+
+// disconnect closes broken connection, so next Do would reconnect
+func (w *wsWorker) disconnect(err error) error {
+	w.conn.Close()
+	w.conn = nil
+	wsDisconnects.With(prometheus.Labels{"reason": disconnectReason(err)}).Inc()
+	if ne, ok := err.(net.Error); ok && ne.Timeout() {
+		return fasthttp.ErrTimeout
+	}
+	return err
+}
+
+// disconnectReason returns short description of err,
+// which broke connection. Close frames are described by their code
+func disconnectReason(err error) string {
+	var ce *websocket.CloseError
+	switch {
+	case stderrors.As(err, &ce):
+		return fmt.Sprintf("close %d", ce.Code)
+	case stderrors.Is(err, io.EOF), stderrors.Is(err, io.ErrUnexpectedEOF):
+		return "eof"
+	case stderrors.Is(err, syscall.ECONNRESET):
+		return "reset"
+	}
+	if ne, ok := err.(net.Error); ok && ne.Timeout() {
+		return "timeout"
+	}
+	return "error"
+}
+
+// Close closes connection of worker
+func (w *wsWorker) Close() error {
+	if w.conn == nil {
+		return nil
+	}
+	w.conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+	return w.conn.Close()
+}
@@ -0,0 +1,102 @@
+package fastclient
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/valyala/fasthttp"
+)
+
+// newWSServer returns WebSocket server, which echoes messages
+// and closes connection with going away code on "bye" message
+func newWSServer() *httptest.Server {
+	upgrader := websocket.Upgrader{}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			mt, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if string(data) == "bye" {
+				conn.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseGoingAway, ""), time.Now().Add(time.Second))
+				return
+			}
+			if err := conn.WriteMessage(mt, data); err != nil {
+				return
+			}
+		}
+	}))
+}
+
+func TestWebsocket(t *testing.T) {
+	srv := newWSServer()
+	defer srv.Close()
+
+	req := new(fasthttp.Request)
+	req.SetRequestURI("ws" + strings.TrimPrefix(srv.URL, "http"))
+	req.SetBodyString("{{if eq .Seq 2}}bye{{else}}msg {{.Worker}}-{{.Seq}}{{end}}")
+	wt, err := newWebsocketTransport(req, time.Second, &tls.Config{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	w := wt.Worker().(*wsWorker)
+	defer w.Close()
+
+	before := (*Client)(nil).DisconnectReasons()["close 1001"]
+	resp := new(fasthttp.Response)
+	for i, expected := range []string{"msg 1-0", "msg 1-1", "", "msg 1-3"} {
+		err := w.Do(req, resp)
+		if expected == "" {
+			if err == nil {
+				t.Fatalf("Expected error on message %d", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Unexpected error on message %d: %s", i, err)
+		}
+		if resp.StatusCode() != fasthttp.StatusOK {
+			t.Errorf("Unexpected status code. Got: %d; Expected: %d", resp.StatusCode(), fasthttp.StatusOK)
+		}
+		if body := string(resp.Body()); body != expected {
+			t.Errorf("Unexpected reply. Got: %q; Expected: %q", body, expected)
+		}
+	}
+	if n := (*Client)(nil).DisconnectReasons()["close 1001"] - before; n != 1 {
+		t.Errorf("Unexpected number of disconnects by close 1001. Got: %d; Expected: %d", n, 1)
+	}
+}
+
+func TestDisconnectReason(t *testing.T) {
+	testCases := []struct {
+		err      error
+		expected string
+	}{
+		{&websocket.CloseError{Code: websocket.CloseMessageTooBig}, "close 1009"},
+		{io.ErrUnexpectedEOF, "eof"},
+		{&net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}, "reset"},
+		{&net.OpError{Op: "read", Err: os.ErrDeadlineExceeded}, "timeout"},
+		{fmt.Errorf("unknown"), "error"},
+	}
+	for _, tc := range testCases {
+		if reason := disconnectReason(tc.err); reason != tc.expected {
+			t.Errorf("Unexpected reason of %v. Got: %q; Expected: %q", tc.err, reason, tc.expected)
+		}
+	}
+}
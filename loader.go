@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"sort"
 	"time"
 
 	"github.com/cheggaaa/pb"
@@ -13,17 +14,6 @@ import (
 	"github.com/hagen1778/fasthttploader/report"
 )
 
-const (
-	// Duration of burst-testing, without qps-limit. Used to estimate start test conditions
-	calibrateDuration = 10 * time.Second
-
-	// Duration of adjustable testing, while trying to reach max qps with minimal lvl of errors
-	adjustmentDuration = 30 * time.Second
-
-	// Period of sample taking, while testing
-	samplePeriod = 500 * time.Millisecond
-)
-
 var (
 	// client do http requests, populate metrics
 	client *fastclient.Client
@@ -34,12 +24,27 @@ var (
 	// errors storage of errors amount in current step. Used to compare changes in errors-metric
 	errors uint64
 
+	// requests storage of requests amount in current step. Used to calculate errors rate
+	requests uint64
+
 	// multiplier is a coefficient of qps multiplying during tests
-	multiplier = float64(0.1)
+	multiplier float64
 
 	throttle = ratelimiter.NewLimiter()
 )
 
+// Phases and actions of burst and calibrate decisions
+const (
+	phaseBurst     = "burst"
+	phaseCalibrate = "calibrate"
+
+	actionBurstResult    = "burst result"
+	actionAddWorkers     = "add workers"
+	actionIncreaseQps    = "increase qps"
+	actionBackOff        = "back-off"
+	actionLatencyBackOff = "latency back-off"
+)
+
 type loadConfig struct {
 	// qps is the rate limit.
 	qps float64
@@ -54,14 +59,34 @@ func run() {
 		RequestDuration: make(map[float64][]float64),
 		Interval:        samplePeriod.Seconds(),
 	}
+	multiplier = *startMultiplier
 
 	cfg := loadConfig{}
-	if *q == 0 {
-		fmt.Println("Run burst-load phase")
-		burstThroughput(&cfg)
+	if *search {
+		if !restoreCalibration(&cfg) {
+			fmt.Println("Run capacity search phase")
+			if err := searchCapacity(&cfg); err != nil {
+				// steps are still useful to see why search failed
+				writeReport()
+				log.Fatalf("Error while searching capacity: %s", err)
+			}
+			saveCalibration(&cfg)
+		}
+	} else if *q == 0 || *skipBurst {
+		if !restoreCalibration(&cfg) {
+			cfg.qps = float64(*q)
+			cfg.c = *c
+			if !*skipBurst {
+				fmt.Println("Run burst-load phase")
+				burstThroughput(&cfg)
+			}
 
-		fmt.Println("Run calibrate phase")
-		calibrateThroughput(&cfg)
+			if !*skipAdjustment {
+				fmt.Println("Run calibrate phase")
+				calibrateThroughput(&cfg)
+			}
+			saveCalibration(&cfg)
+		}
 	} else {
 		cfg.qps = float64(*q)
 		cfg.c = *c
@@ -69,7 +94,10 @@ func run() {
 
 	fmt.Println("Run load phase")
 	makeLoad(&cfg)
+	writeReport()
+}
 
+func writeReport() {
 	f, err := os.Create(*fileName)
 	if err != nil {
 		log.Fatalf("Error while trying to create file: %s", err)
@@ -78,22 +106,46 @@ func run() {
 	f.Close()
 }
 
+func newClient() *fastclient.Client {
+	c, err := fastclient.New(req, *t, *successStatusCode)
+	if err != nil {
+		log.Fatalf("cannot create client: %s", err)
+	}
+	return c
+}
+
 func burstThroughput(cfg *loadConfig) {
-	client = fastclient.New(req, *t, *successStatusCode)
+	client = newClient()
 	startTime := time.Now()
-	timeout := time.After(calibrateDuration)
-	bar, progressTicker := acquireProgressBar(calibrateDuration)
+	timeout := time.After(*burstDuration)
+	bar, progressTicker := acquireProgressBar(*burstDuration)
 
 	client.RunWorkers(*c)
 	for {
 		select {
 		case <-timeout:
 			finishProgressBar(bar)
-			cfg.qps = float64(client.RequestSum()) / calibrateDuration.Seconds()
+			cfg.qps = float64(client.RequestSum()) / burstDuration.Seconds()
 			cfg.c = client.Amount()
-			if (client.Errors()/client.RequestSum())*100 > 2 { // just more than 2% of errors
+			logDecision(report.Decision{
+				Phase:   phaseBurst,
+				Action:  actionBurstResult,
+				Qps:     cfg.qps,
+				Workers: cfg.c,
+				Errors:  client.Errors(),
+				Reason:  fmt.Sprintf("%d requests done in %s", client.RequestSum(), *burstDuration),
+			})
+			if errorRate := float64(client.Errors()) / float64(client.RequestSum()) * 100; errorRate > *burstErrorRate {
 				cfg.qps /= 2
 				cfg.c /= 2
+				logDecision(report.Decision{
+					Phase:   phaseBurst,
+					Action:  actionBackOff,
+					Qps:     cfg.qps,
+					Workers: cfg.c,
+					Errors:  client.Errors(),
+					Reason:  fmt.Sprintf("errors rate %.2f%% is more than %.2f%%: QPS and workers halved", errorRate, *burstErrorRate),
+				})
 			}
 			printSummary("Burst Throughput", startTime)
 			return
@@ -106,16 +158,16 @@ func burstThroughput(cfg *loadConfig) {
 }
 
 func calibrateThroughput(cfg *loadConfig) {
-	client = fastclient.New(req, *t, *successStatusCode)
+	client = newClient()
 	t := time.Now()
 	ctx, cancel := context.WithCancel(context.Background())
 
 	throttle.SetLimit(cfg.qps)
 	client.RunWorkers(cfg.c)
 	go func() {
-		timeout := time.After(adjustmentDuration)
-		sampler := time.Tick(samplePeriod)
-		bar, progressTicker := acquireProgressBar(adjustmentDuration)
+		timeout := time.After(*adjustmentDuration)
+		sampler := time.Tick(*samplePeriod)
+		bar, progressTicker := acquireProgressBar(*adjustmentDuration)
 		for {
 			select {
 			case <-timeout:
@@ -145,29 +197,70 @@ func calibrate() {
 		return
 	}
 
-	if !isFlawed() {
+	// errors and requests baselines are advanced on every sample,
+	// so errors rate isn't mixed up with samples of latency back-off
+	flawed := isFlawed()
+	if lt, v, ok := exceededLatency(calibrateLatency, client.RecentRequestDuration()); ok {
+		throttle.SetLimit(throttle.Limit() / (1 + multiplier))
+		multiplier /= *multiplierDecay
+		await += *backoffAwait
+		logCalibrateDecision(actionLatencyBackOff, fmt.Sprintf("latency %.4fs exceeds target %s: QPS decreased to %.2f", v, lt, throttle.Limit()))
+		return
+	}
+
+	if !flawed {
 		if client.Overflow() > 0 {
 			n := int(float64(client.Amount()) * multiplier)
 			client.RunWorkers(n)
-			await += 1
+			await += *increaseAwait
+			logCalibrateDecision(actionAddWorkers, fmt.Sprintf("jobs overflow: %d workers added", n))
 		} else {
 			throttle.SetLimit(throttle.Limit() * (1 + multiplier))
-			await += 1
+			await += *increaseAwait
+			logCalibrateDecision(actionIncreaseQps, fmt.Sprintf("no new errors: QPS increased to %.2f", throttle.Limit()))
 		}
 	} else {
-		multiplier /= 1.2
-		await += 3
+		multiplier /= *multiplierDecay
+		await += *backoffAwait
+		logCalibrateDecision(actionBackOff, fmt.Sprintf("errors rate exceeds %.2f%%: multiplier decreased to %f", *backoffErrorRate, multiplier))
 	}
 }
 
+// logCalibrateDecision adds decision of calibrate phase
+// along with state observed after decision is applied
+func logCalibrateDecision(action, reason string) {
+	logDecision(report.Decision{
+		Phase:      phaseCalibrate,
+		Action:     action,
+		Qps:        throttle.Limit(),
+		Workers:    client.Amount(),
+		Multiplier: multiplier,
+		Errors:     client.Errors(),
+		Overflow:   client.Overflow(),
+		Reason:     reason,
+	})
+}
+
+// logDecision adds decision to report
+func logDecision(d report.Decision) {
+	if *debug {
+		fmt.Printf("[%s] %s: %s\n", d.Phase, d.Action, d.Reason)
+	}
+
+	r.Lock()
+	d.Time = float64(len(r.Qps)) * r.Interval
+	r.Decisions = append(r.Decisions, d)
+	r.Unlock()
+}
+
 func makeLoad(cfg *loadConfig) {
-	client = fastclient.New(req, *t, *successStatusCode)
+	client = newClient()
 	startTime := time.Now()
 	ctx, cancel := context.WithCancel(context.Background())
 	throttle.SetLimit(cfg.qps)
 	client.RunWorkers(cfg.c)
 	go func() {
-		stateTick := time.Tick(samplePeriod)
+		stateTick := time.Tick(*samplePeriod)
 		timeout := time.After(*d)
 		bar, progressTicker := acquireProgressBar(*d)
 		for {
@@ -201,24 +294,100 @@ func printState() {
 	r.Connections = append(r.Connections, client.ConnOpen())
 	r.Errors = append(r.Errors, client.Errors())
 	r.Timeouts = append(r.Timeouts, client.Timeouts())
+	r.HandshakeErrors = append(r.HandshakeErrors, client.HandshakeErrors())
+	r.NewConnections = append(r.NewConnections, client.NewConnections())
+	r.PortsExhausted = append(r.PortsExhausted, client.PortsExhausted())
+	r.ProxyErrors = append(r.ProxyErrors, client.ProxyErrors())
 	r.RequestSum = append(r.RequestSum, client.RequestSum())
 	r.RequestSuccess = append(r.RequestSuccess, client.RequestSuccess())
 	r.BytesWritten = append(r.BytesWritten, client.BytesWritten())
 	r.BytesRead = append(r.BytesRead, client.BytesRead())
 	r.Qps = append(r.Qps, uint64(throttle.Limit()))
 	r.StatusCodes = client.StatusCodes()
+	r.StatusGroups = client.StatusGroups()
 	r.ErrorMessages = client.ErrorMessages()
+	r.AssertionFailures = client.AssertionFailures()
+	r.IPTraffic = r.IPTraffic[:0]
+	for ip, t := range client.IPTraffic() {
+		r.IPTraffic = append(r.IPTraffic, report.IPTraffic{
+			IP:           ip,
+			Connections:  t.Connections,
+			BytesWritten: t.BytesWritten,
+			BytesRead:    t.BytesRead,
+		})
+	}
+	sort.Slice(r.IPTraffic, func(i, j int) bool { return r.IPTraffic[i].IP < r.IPTraffic[j].IP })
+	r.FlowSteps = r.FlowSteps[:0]
+	for _, s := range client.FlowSteps() {
+		r.FlowSteps = append(r.FlowSteps, report.FlowStep{
+			Name:     s.Name,
+			Requests: s.Requests,
+			Failures: s.Failures,
+			P50:      s.Duration[0.5],
+			P99:      s.Duration[0.99],
+		})
+	}
+	if client.TLS() {
+		r.TLS = true
+		r.Handshakes = append(r.Handshakes, client.Handshakes())
+		r.ResumedHandshakes = append(r.ResumedHandshakes, client.ResumedHandshakes())
+		r.UpdateHandshakeDuration(client.HandshakeDuration())
+	}
+	if client.Auth() {
+		r.Auth = true
+		r.AuthFailures = append(r.AuthFailures, client.AuthFailures())
+		r.TokenErrors = append(r.TokenErrors, client.TokenErrors())
+	}
+	if client.Script() {
+		r.Script = true
+		r.ScriptErrors = append(r.ScriptErrors, client.ScriptErrors())
+	}
+	if client.Sessions() {
+		r.Sessions = true
+		r.Logins = append(r.Logins, client.Logins())
+		r.LoginErrors = append(r.LoginErrors, client.LoginErrors())
+		r.SessionResets = append(r.SessionResets, client.SessionResets())
+	}
+	if client.FollowRedirects() {
+		r.Redirects = append(r.Redirects, client.Redirects())
+		r.UpdateFirstHopDuration(client.FirstHopDuration())
+	}
+	if client.HTTP2() {
+		r.HTTP2 = true
+		r.Streams = append(r.Streams, client.StreamsOpen())
+		r.StreamsSum = append(r.StreamsSum, client.StreamsSum())
+		r.StreamErrors = append(r.StreamErrors, client.StreamErrors())
+	}
+	if client.Websocket() {
+		r.Websocket = true
+		r.Disconnects = append(r.Disconnects, client.Disconnects())
+		r.DisconnectReasons = client.DisconnectReasons()
+		r.UpdateConnectDuration(client.ConnectDuration())
+	}
+	if client.EventStream() {
+		r.EventStream = true
+		r.EventStreamDrops = append(r.EventStreamDrops, client.EventStreamDrops())
+		r.Events = append(r.Events, client.Events())
+		r.UpdateEventStream(client.EventStreamTTFB(), client.EventStreamDuration(), client.EventGap())
+	}
 	r.UpdateRequestDuration(client.RequestDuration())
+	r.Phases = fastclient.Phases
+	r.UpdatePhaseDuration(client.PhaseDuration())
 	r.Unlock()
 }
 
+// isFlawed returns true if errors rate since previous check
+// exceeds backoffErrorRate
 func isFlawed() bool {
-	if client.Errors() > 0 && errors != client.Errors() {
-		errors = client.Errors()
-		return true
-	}
+	e, n := client.Errors(), client.RequestSum()
+	defer func() {
+		errors, requests = e, n
+	}()
 
-	return false
+	if e <= errors || n <= requests {
+		return false
+	}
+	return float64(e-errors)/float64(n-requests)*100 > *backoffErrorRate
 }
 
 func load(ctx context.Context) {
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hagen1778/fasthttploader/fastclient"
+	"github.com/hagen1778/fasthttploader/report"
+	"github.com/valyala/fasthttp"
+)
+
+// stubTransport responds after delay with error if fail is set
+type stubTransport struct {
+	delay time.Duration
+	fail  bool
+}
+
+func (st *stubTransport) Do(req *fasthttp.Request, resp *fasthttp.Response) error {
+	time.Sleep(st.delay)
+	if st.fail {
+		return fmt.Errorf("stub error")
+	}
+	resp.SetStatusCode(fasthttp.StatusOK)
+	return nil
+}
+
+// runStubClient sets client with stub transport and sends n requests
+func runStubClient(t *testing.T, st *stubTransport, n uint64) {
+	t.Helper()
+	req := new(fasthttp.Request)
+	req.SetRequestURI("http://localhost/")
+	client = fastclient.NewWithTransport(req, st, "200")
+	client.RunWorkers(1)
+	for i := uint64(0); i < n; i++ {
+		client.Jobsch <- struct{}{}
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for client.RequestSum() < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timeout while waiting for requests. Got: %d; Expected: %d", client.RequestSum(), n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	r = &report.Page{Interval: 1}
+	errors, requests, await = 0, 0, 0
+	multiplier = 1
+	throttle.SetLimit(100)
+}
+
+func TestCalibrateLatencyBackOff(t *testing.T) {
+	if err := calibrateLatency.Set("p50:1ms"); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	defer func() { calibrateLatency = nil }()
+	runStubClient(t, &stubTransport{delay: 5 * time.Millisecond, fail: true}, 3)
+	defer client.Flush()
+
+	calibrate()
+	if len(r.Decisions) != 1 || r.Decisions[0].Action != actionLatencyBackOff {
+		t.Fatalf("Unexpected decisions. Got: %v; Expected: %q", r.Decisions, actionLatencyBackOff)
+	}
+	// decision contains state after it is applied
+	if d := r.Decisions[0]; d.Qps != 50 || d.Multiplier != 1 / *multiplierDecay {
+		t.Errorf("Unexpected decision state. Got: qps=%f multiplier=%f; Expected: qps=%f multiplier=%f", d.Qps, d.Multiplier, 50.0, 1 / *multiplierDecay)
+	}
+	// baselines are advanced on latency back-off as well
+	if errors != client.Errors() || requests != client.RequestSum() {
+		t.Errorf("Unexpected baselines. Got: %d errors, %d requests; Expected: %d errors, %d requests",
+			errors, requests, client.Errors(), client.RequestSum())
+	}
+}
+
+func TestCalibrateDecisions(t *testing.T) {
+	runStubClient(t, &stubTransport{delay: 100 * time.Millisecond}, 1)
+	defer client.Flush()
+
+	// no errors: QPS is increased
+	calibrate()
+	// worker is busy with the first job, so the second one overflows
+	client.Jobsch <- struct{}{}
+	client.Jobsch <- struct{}{}
+	await = 0
+	calibrate()
+	expected := []report.Decision{
+		{Phase: phaseCalibrate, Action: actionIncreaseQps, Qps: 200, Workers: 1, Multiplier: 1},
+		{Phase: phaseCalibrate, Action: actionAddWorkers, Qps: 200, Workers: 2, Multiplier: 1},
+	}
+	if len(r.Decisions) != len(expected) {
+		t.Fatalf("Unexpected number of decisions. Got: %d; Expected: %d", len(r.Decisions), len(expected))
+	}
+	for i, d := range r.Decisions {
+		// decision contains state after it is applied
+		e := expected[i]
+		if d.Phase != e.Phase || d.Action != e.Action || d.Qps != e.Qps || d.Workers != e.Workers || d.Multiplier != e.Multiplier {
+			t.Errorf("Unexpected decision #%d. Got: %s %s qps=%f workers=%d multiplier=%f; Expected: %s %s qps=%f workers=%d multiplier=%f",
+				i, d.Phase, d.Action, d.Qps, d.Workers, d.Multiplier, e.Phase, e.Action, e.Qps, e.Workers, e.Multiplier)
+		}
+	}
+}
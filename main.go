@@ -24,21 +24,53 @@ var (
 	fileName = flag.String("r", "report.html", "Set filename to store final report")
 	web      = flag.Bool("web", false, "Auto open generated report at browser")
 
-	d = flag.Duration("d", 30*time.Second, "Cant be less than 20sec")
+	d = flag.Duration("d", 30*time.Second, "Duration of load phase. Cant be less than samplePeriod")
 	t = flag.Duration("t", 5*time.Second, "Request timeout")
 	q = flag.Int("q", 0, "Request per second limit. Detect automatically, if not setted")
 	c = flag.Int("c", 500, "Number of supposed clients")
 
+	burstDuration      = flag.Duration("burstDuration", 10*time.Second, "Duration of burst phase, without QPS limit. Used to estimate start conditions")
+	adjustmentDuration = flag.Duration("adjustmentDuration", 30*time.Second, "Duration of calibrate phase, while trying to reach max QPS with minimal errors")
+	samplePeriod       = flag.Duration("samplePeriod", 500*time.Millisecond, "Period of taking samples for report and calibration")
+	skipBurst          = flag.Bool("skipBurst", false, "Skip burst phase and start calibrate phase from -q and -c")
+	skipAdjustment     = flag.Bool("skipAdjustment", false, "Skip calibrate phase and use results of burst phase for load")
+	burstErrorRate     = flag.Float64("burstErrorRate", 2, "Percent of errors during burst phase, above which QPS and clients are halved")
+	backoffErrorRate   = flag.Float64("backoffErrorRate", 0, "Percent of errors since previous adjustment, above which calibrate phase backs off")
+	startMultiplier    = flag.Float64("multiplier", 0.1, "Initial coefficient of QPS and clients increasing during calibrate phase")
+	multiplierDecay    = flag.Float64("multiplierDecay", 1.2, "Divisor of multiplier on every back-off during calibrate phase")
+	increaseAwait      = flag.Int("increaseAwait", 1, "Number of samples to wait after increasing QPS or clients during calibrate phase")
+	backoffAwait       = flag.Int("backoffAwait", 3, "Number of samples to wait after back-off during calibrate phase")
+
+	calibration = flag.String("calibration", "", "File to store detected QPS and number of clients. "+
+		"If file exists, burst and calibrate phases or capacity search are skipped and stored values are used")
+
 	debug              = flag.Bool("debug", false, "Print debug messages if true")
 	disableKeepAlive   = flag.Bool("k", false, "Disable keepalive if true")
 	disableCompression = flag.Bool("disable-compression", false, "Disables compression if true")
-	successStatusCode  = flag.Int("successStatusCode", fasthttp.StatusOK, "Status code on which a successful request would be determined")
+	successStatusCode  = flag.String("successStatusCode", "200", "Comma-separated list of status codes and ranges on which a successful request would be determined, e.g. 200-299,304")
+
+	search       = flag.Bool("search", false, "Run capacity search instead of burst and calibrate phases")
+	searchStart  = flag.Int("searchStart", 100, "Initial QPS of capacity search")
+	searchStep   = flag.Float64("searchStep", 0.5, "Coefficient of QPS increase between capacity search steps")
+	searchHold   = flag.Duration("searchHold", 10*time.Second, "Duration of each capacity search step")
+	searchRefine = flag.Int("searchRefine", 3, "Number of bisection steps between last passed and first failed QPS")
+	sloLatency   = flag.Duration("sloLatency", 500*time.Millisecond, "Max p99 latency at which capacity search step is passed")
+	sloErrors    = flag.Float64("sloErrors", 1, "Max percent of errors at which capacity search step is passed")
 
 	cpuprofile = flag.String("cpuprofile", "", "write cpu profile to file")
 	memprofile = flag.String("memprofile", "", "write memory profile to this file")
 )
 
+// calibrateLatency contains latency targets for calibrate phase
+var calibrateLatency latencyTargets
+
+func init() {
+	flag.Var(&calibrateLatency, "calibrateLatency", "Comma-separated list of latency targets for calibrate phase, e.g. p95:100ms,p99:300ms. "+
+		"QPS would be decreased if recent latency exceeds any of them")
+}
+
 var usage = `Usage: fasthttploader [options...] <url>
+Url could be unix:/path/to/sock[:/request/uri] to send requests over Unix domain socket.
 Notice: fasthttploader would force aggressive burst stages before testing to detect max qps and number for clients.
 To avoid this you need to set -c and -q parameters.
 Options:
@@ -57,8 +89,8 @@ func main() {
 		usageAndExit("")
 	}
 
-	if *d < time.Second*20 {
-		usageAndExit("Duration cant be less than 20s")
+	if err := validateFlags(); err != nil {
+		usageAndExit(err.Error())
 	}
 
 	if *cpuprofile != "" {
@@ -98,6 +130,43 @@ func main() {
 	}
 }
 
+func validateFlags() error {
+	if *samplePeriod <= 0 {
+		return fmt.Errorf("samplePeriod must be positive")
+	}
+	if *d < *samplePeriod {
+		return fmt.Errorf("Duration cant be less than samplePeriod %s", *samplePeriod)
+	}
+	if *search && (*searchStart < 1 || *searchStep <= 0 || *searchHold < *samplePeriod) {
+		return fmt.Errorf("Capacity search requires searchStart >= 1, searchStep > 0 and searchHold >= %s", *samplePeriod)
+	}
+	if *skipBurst && *q < 1 {
+		return fmt.Errorf("skipBurst requires -q to be set as initial QPS")
+	}
+	if !*skipBurst && *burstDuration <= 0 {
+		return fmt.Errorf("burstDuration must be positive")
+	}
+	if !*skipAdjustment && *adjustmentDuration < *samplePeriod {
+		return fmt.Errorf("adjustmentDuration cant be less than samplePeriod %s", *samplePeriod)
+	}
+	if *burstErrorRate < 0 || *burstErrorRate > 100 {
+		return fmt.Errorf("burstErrorRate must be in range [0, 100]")
+	}
+	if *backoffErrorRate < 0 || *backoffErrorRate > 100 {
+		return fmt.Errorf("backoffErrorRate must be in range [0, 100]")
+	}
+	if *startMultiplier <= 0 {
+		return fmt.Errorf("multiplier must be positive")
+	}
+	if *multiplierDecay <= 1 {
+		return fmt.Errorf("multiplierDecay must be greater than 1")
+	}
+	if *increaseAwait < 0 || *backoffAwait < 0 {
+		return fmt.Errorf("increaseAwait and backoffAwait cant be negative")
+	}
+	return nil
+}
+
 var re = regexp.MustCompile("^([\\w-]+):\\s*(.+)")
 
 func applyHeaders() {
@@ -133,7 +202,7 @@ func usageAndExit(msg string) {
 	flag.Usage()
 	if msg != "" {
 		fmt.Print("----------------------------\nErr: ")
-		fmt.Fprintf(os.Stderr, msg)
+		fmt.Fprint(os.Stderr, msg)
 		fmt.Fprintf(os.Stderr, "\n\n")
 	}
 	os.Exit(1)
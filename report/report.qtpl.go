@@ -1,16 +1,9 @@
-// This file is automatically generated by qtc from "report.qtpl".
+// Code generated by qtc from "report.qtpl". DO NOT EDIT.
 // See https://github.com/valyala/quicktemplate for details.
 
 //line report/report.qtpl:1
 package report
 
-//line report/report.qtpl:1
-import (
-	qtio422016 "io"
-
-	qt422016 "github.com/valyala/quicktemplate"
-)
-
 //line report/report.qtpl:1
 import (
 	"sort"
@@ -18,6 +11,13 @@ import (
 	"sync"
 )
 
+//line report/report.qtpl:7
+import (
+	qtio422016 "io"
+
+	qt422016 "github.com/valyala/quicktemplate"
+)
+
 //line report/report.qtpl:7
 var (
 	_ = qtio422016.Copy
@@ -34,58 +34,273 @@ type Page struct {
 
 	sync.Mutex
 	Connections     []uint64
+	NewConnections  []uint64
 	RequestSum      []uint64
 	RequestSuccess  []uint64
 	Errors          []uint64
 	Timeouts        []uint64
+	HandshakeErrors []uint64
+	PortsExhausted  []uint64
+	ProxyErrors     []uint64
 	Qps             []uint64
 	BytesWritten    []uint64
 	BytesRead       []uint64
 	RequestDuration map[float64][]float64
-	StatusCodes     map[string]float64
-	ErrorMessages   map[string]int
+
+	// Phases are names of request phases in order of their execution
+	Phases        []string
+	PhaseDuration map[string][]float64
+
+	StatusCodes map[string]float64
+
+	// StatusGroups maps status codes to their groups:
+	// success, client error, server error, error or other
+	StatusGroups  map[string]string
+	ErrorMessages map[string]int
+
+	// AssertionFailures contains number of responses failed every assertion
+	AssertionFailures map[string]uint64
+
+	// IPTraffic contains traffic distribution by server IPs
+	IPTraffic []IPTraffic
+
+	// Auth is true if credentials were attached to requests
+	Auth         bool
+	AuthFailures []uint64
+	TokenErrors  []uint64
+
+	// Script is true if requests were processed by script
+	Script       bool
+	ScriptErrors []uint64
+
+	// FlowSteps contains stats of user flow steps in order of their execution
+	FlowSteps []FlowStep
+
+	// Sessions is true if workers kept sessions
+	Sessions      bool
+	Logins        []uint64
+	LoginErrors   []uint64
+	SessionResets []uint64
+
+	// Redirects is a number of followed redirects
+	Redirects        []uint64
+	FirstHopDuration map[float64][]float64
+
+	// TLS is true if TLS connections were established
+	TLS               bool
+	Handshakes        []uint64
+	ResumedHandshakes []uint64
+	HandshakeDuration map[float64][]float64
+
+	// HTTP2 is true if requests were sent over HTTP/2
+	HTTP2        bool
+	Streams      []uint64
+	StreamsSum   []uint64
+	StreamErrors []uint64
+
+	// Websocket is true if messages were sent over WebSocket
+	Websocket   bool
+	Disconnects []uint64
+	// DisconnectReasons contains number of disconnects by reason
+	DisconnectReasons map[string]uint64
+	ConnectDuration   map[float64][]float64
+
+	// EventStream is true if event streams were kept open
+	EventStream         bool
+	EventStreamDrops    []uint64
+	Events              []uint64
+	EventStreamTTFB     map[float64][]float64
+	EventStreamDuration map[float64][]float64
+	EventGap            map[float64][]float64
+
+	// Steps contains results of capacity search steps
+	Steps []Step
+
+	// SustainableQps is the highest QPS passed capacity search
+	SustainableQps float64
+
+	// Decisions contains choices made during burst and calibrate phases
+	Decisions []Decision
+}
+
+// Decision represents choice made during burst or calibrate phase
+type Decision struct {
+	// Time is a number of seconds since test start
+	Time float64
+
+	// Phase is a name of phase where decision was made
+	Phase string
+
+	// Action is a short name of decision
+	Action string
+
+	// Qps is a rate limit at the moment of decision
+	Qps float64
+
+	// Workers is a number of workers at the moment of decision
+	Workers int
+
+	// Multiplier is a coefficient of QPS multiplying at the moment of decision
+	Multiplier float64
+
+	// Errors is a number of observed errors
+	Errors uint64
+
+	// Overflow is a length of jobs queue
+	Overflow int
+
+	// Reason explains decision
+	Reason string
+}
+
+// IPTraffic represents amount of traffic sent to single IP
+type IPTraffic struct {
+	IP           string
+	Connections  uint64
+	BytesWritten uint64
+	BytesRead    uint64
+}
+
+// FlowStep represents stats of user flow step
+type FlowStep struct {
+	Name     string
+	Requests uint64
+	Failures uint64
+
+	// P50 and P99 are latency quantiles in seconds
+	P50 float64
+	P99 float64
+}
+
+// Step represents result of capacity search step
+type Step struct {
+	// Qps is a rate limit of the step
+	Qps float64
+
+	// Achieved is an actual rate of sent requests
+	Achieved float64
+
+	// P99 is a 0.99 quantile of latency in seconds
+	P99 float64
+
+	// ErrorRate is a percent of errors
+	ErrorRate float64
+
+	// Passed is true if step satisfied SLO
+	Passed bool
 }
 
 type seriesFunc func() string
 
-//line report/report.qtpl:32
+// statusGroup contains status codes of the same group
+type statusGroup struct {
+	name  string
+	color string
+	total float64
+	codes []string
+}
+
+// groupStatusCodes splits status codes into groups
+// ordered by severity. Codes within group are sorted
+func (p *Page) groupStatusCodes() []*statusGroup {
+	groups := []*statusGroup{
+		{name: "success", color: "#5cb85c"},
+		{name: "client error", color: "#f0ad4e"},
+		{name: "server error", color: "#d9534f"},
+		{name: "error", color: "#843534"},
+		{name: "other", color: "#777777"},
+	}
+	var result []*statusGroup
+	for _, g := range groups {
+		for code, v := range p.StatusCodes {
+			name, ok := p.StatusGroups[code]
+			if !ok {
+				name = "other"
+			}
+			if name == g.name {
+				g.codes = append(g.codes, code)
+				g.total += v
+			}
+		}
+		if len(g.codes) > 0 {
+			sort.Strings(g.codes)
+			result = append(result, g)
+		}
+	}
+	return result
+}
+
+// UpdateConnectDuration appends quantiles of WebSocket connect latency
+func (p *Page) UpdateConnectDuration(d map[float64]float64) {
+	appendQuantiles(&p.ConnectDuration, d)
+}
+
+// UpdatePhaseDuration appends median latency of request phases
+func (p *Page) UpdatePhaseDuration(d map[string]float64) {
+	if p.PhaseDuration == nil {
+		p.PhaseDuration = make(map[string][]float64)
+	}
+	for k, v := range d {
+		p.PhaseDuration[k] = append(p.PhaseDuration[k], v)
+	}
+}
+
+// UpdateFirstHopDuration appends quantiles of the first hop latency of redirect chains
+func (p *Page) UpdateFirstHopDuration(d map[float64]float64) {
+	appendQuantiles(&p.FirstHopDuration, d)
+}
+
+// UpdateHandshakeDuration appends quantiles of TLS handshake latency
+func (p *Page) UpdateHandshakeDuration(d map[float64]float64) {
+	appendQuantiles(&p.HandshakeDuration, d)
+}
+
+// UpdateEventStream appends quantiles of event streams metrics
+func (p *Page) UpdateEventStream(ttfb, duration, gap map[float64]float64) {
+	appendQuantiles(&p.EventStreamTTFB, ttfb)
+	appendQuantiles(&p.EventStreamDuration, duration)
+	appendQuantiles(&p.EventGap, gap)
+}
+
+//line report/report.qtpl:246
 func (p *Page) streamtitle(qw422016 *qt422016.Writer) {
-//line report/report.qtpl:32
-qw422016.E().S(p.Title) }
+//line report/report.qtpl:246
+	qw422016.E().S(p.Title)
+//line report/report.qtpl:246
+}
 
-//line report/report.qtpl:32
-//line report/report.qtpl:32
+//line report/report.qtpl:246
 func (p *Page) writetitle(qq422016 qtio422016.Writer) {
-	//line report/report.qtpl:32
+//line report/report.qtpl:246
 	qw422016 := qt422016.AcquireWriter(qq422016)
-	//line report/report.qtpl:32
+//line report/report.qtpl:246
 	p.streamtitle(qw422016)
-	//line report/report.qtpl:32
+//line report/report.qtpl:246
 	qt422016.ReleaseWriter(qw422016)
-//line report/report.qtpl:32
+//line report/report.qtpl:246
 }
 
-//line report/report.qtpl:32
+//line report/report.qtpl:246
 func (p *Page) title() string {
-	//line report/report.qtpl:32
+//line report/report.qtpl:246
 	qb422016 := qt422016.AcquireByteBuffer()
-	//line report/report.qtpl:32
+//line report/report.qtpl:246
 	p.writetitle(qb422016)
-	//line report/report.qtpl:32
+//line report/report.qtpl:246
 	qs422016 := string(qb422016.B)
-	//line report/report.qtpl:32
+//line report/report.qtpl:246
 	qt422016.ReleaseByteBuffer(qb422016)
-	//line report/report.qtpl:32
+//line report/report.qtpl:246
 	return qs422016
-//line report/report.qtpl:32
+//line report/report.qtpl:246
 }
 
-//line report/report.qtpl:34
+//line report/report.qtpl:248
 func (p *Page) StreamUpdateRequestDuration(qw422016 *qt422016.Writer, d map[float64]float64) {
-	//line report/report.qtpl:34
+//line report/report.qtpl:248
 	qw422016.N().S(`
 	`)
-	//line report/report.qtpl:36
+//line report/report.qtpl:250
 	for k, v := range d {
 		if _, ok := p.RequestDuration[k]; !ok {
 			p.RequestDuration[k] = make([]float64, 0)
@@ -94,152 +309,302 @@ func (p *Page) StreamUpdateRequestDuration(qw422016 *qt422016.Writer, d map[floa
 		p.RequestDuration[k] = append(p.RequestDuration[k], v)
 	}
 
-	//line report/report.qtpl:43
+//line report/report.qtpl:257
 	qw422016.N().S(`
 `)
-//line report/report.qtpl:44
+//line report/report.qtpl:258
 }
 
-//line report/report.qtpl:44
+//line report/report.qtpl:258
 func (p *Page) WriteUpdateRequestDuration(qq422016 qtio422016.Writer, d map[float64]float64) {
-	//line report/report.qtpl:44
+//line report/report.qtpl:258
 	qw422016 := qt422016.AcquireWriter(qq422016)
-	//line report/report.qtpl:44
+//line report/report.qtpl:258
 	p.StreamUpdateRequestDuration(qw422016, d)
-	//line report/report.qtpl:44
+//line report/report.qtpl:258
 	qt422016.ReleaseWriter(qw422016)
-//line report/report.qtpl:44
+//line report/report.qtpl:258
 }
 
-//line report/report.qtpl:44
+//line report/report.qtpl:258
 func (p *Page) UpdateRequestDuration(d map[float64]float64) string {
-	//line report/report.qtpl:44
+//line report/report.qtpl:258
 	qb422016 := qt422016.AcquireByteBuffer()
-	//line report/report.qtpl:44
+//line report/report.qtpl:258
 	p.WriteUpdateRequestDuration(qb422016, d)
-	//line report/report.qtpl:44
+//line report/report.qtpl:258
 	qs422016 := string(qb422016.B)
-	//line report/report.qtpl:44
+//line report/report.qtpl:258
 	qt422016.ReleaseByteBuffer(qb422016)
-	//line report/report.qtpl:44
+//line report/report.qtpl:258
 	return qs422016
-//line report/report.qtpl:44
+//line report/report.qtpl:258
 }
 
-//line report/report.qtpl:46
+//line report/report.qtpl:260
 func StreamPrintPage(qw422016 *qt422016.Writer, p *Page) {
-	//line report/report.qtpl:46
+//line report/report.qtpl:260
 	qw422016.N().S(`
 <html>
 	<head>
 		<title>`)
-	//line report/report.qtpl:49
+//line report/report.qtpl:263
 	p.streamtitle(qw422016)
-	//line report/report.qtpl:49
+//line report/report.qtpl:263
 	qw422016.N().S(`</title>
 		<script type="text/javascript" src="https://ajax.googleapis.com/ajax/libs/jquery/3.1.0/jquery.min.js"></script>
 		<script type="text/javascript" src="https://code.highcharts.com/highcharts.js"></script>
 		<script type="text/javascript" src="https://code.highcharts.com/modules/exporting.js"></script>
 		<script type="text/javascript">`)
-	//line report/report.qtpl:53
+//line report/report.qtpl:267
 	qw422016.N().Z(MustAsset("report/static/js/utils.js"))
-	//line report/report.qtpl:53
+//line report/report.qtpl:267
 	qw422016.N().S(`</script>
 		<style>`)
-	//line report/report.qtpl:54
+//line report/report.qtpl:268
 	qw422016.N().Z(MustAsset("report/static/css/main.css"))
-	//line report/report.qtpl:54
+//line report/report.qtpl:268
 	qw422016.N().S(`</style>
 	</head>
 	 <body>
 		`)
-	//line report/report.qtpl:57
+//line report/report.qtpl:271
 	p.streamsimpleChart(qw422016, "connections", p.connectionSeries)
-	//line report/report.qtpl:57
+//line report/report.qtpl:271
+	qw422016.N().S(`
+		`)
+//line report/report.qtpl:272
+	p.streamsimpleChart(qw422016, "connection-reuse", p.reuseSeries)
+//line report/report.qtpl:272
 	qw422016.N().S(`
 		`)
-	//line report/report.qtpl:58
+//line report/report.qtpl:273
 	p.streamsimpleChart(qw422016, "qps", p.qpsSeries)
-	//line report/report.qtpl:58
+//line report/report.qtpl:273
 	qw422016.N().S(`
 		`)
-	//line report/report.qtpl:59
+//line report/report.qtpl:274
 	p.streamsimpleChart(qw422016, "errors-vs-timeouts", p.errorSeries)
-	//line report/report.qtpl:59
+//line report/report.qtpl:274
 	qw422016.N().S(`
 		`)
-	//line report/report.qtpl:60
+//line report/report.qtpl:275
 	p.streamsimpleChart(qw422016, "latency", p.durationSeries)
-	//line report/report.qtpl:60
+//line report/report.qtpl:275
+	qw422016.N().S(`
+		`)
+//line report/report.qtpl:276
+	p.streamstackedChart(qw422016, "latency-breakdown", p.phaseSeries)
+//line report/report.qtpl:276
+	qw422016.N().S(`
+		`)
+//line report/report.qtpl:277
+	if p.TLS {
+//line report/report.qtpl:277
+		qw422016.N().S(`
+			`)
+//line report/report.qtpl:278
+		p.streamsimpleChart(qw422016, "tls-handshakes", p.handshakeSeries)
+//line report/report.qtpl:278
+		qw422016.N().S(`
+		`)
+//line report/report.qtpl:279
+	}
+//line report/report.qtpl:279
+	qw422016.N().S(`
+		`)
+//line report/report.qtpl:280
+	if p.HTTP2 {
+//line report/report.qtpl:280
+		qw422016.N().S(`
+			`)
+//line report/report.qtpl:281
+		p.streamsimpleChart(qw422016, "http2-streams", p.streamSeries)
+//line report/report.qtpl:281
+		qw422016.N().S(`
+		`)
+//line report/report.qtpl:282
+	}
+//line report/report.qtpl:282
+	qw422016.N().S(`
+		`)
+//line report/report.qtpl:283
+	if p.EventStream {
+//line report/report.qtpl:283
+		qw422016.N().S(`
+			`)
+//line report/report.qtpl:284
+		p.streamsimpleChart(qw422016, "event-streams", p.eventStreamSeries)
+//line report/report.qtpl:284
+		qw422016.N().S(`
+		`)
+//line report/report.qtpl:285
+	}
+//line report/report.qtpl:285
 	qw422016.N().S(`
 		`)
-	//line report/report.qtpl:61
+//line report/report.qtpl:286
 	p.streambytesChart(qw422016, "written-vs-read", p.bytesSeries)
-	//line report/report.qtpl:61
+//line report/report.qtpl:286
 	qw422016.N().S(`
 		`)
-	//line report/report.qtpl:62
+//line report/report.qtpl:287
 	p.streampieChart(qw422016, "status-codes", p.statusCodesSeries)
-	//line report/report.qtpl:62
+//line report/report.qtpl:287
 	qw422016.N().S(`
 		`)
-	//line report/report.qtpl:63
+//line report/report.qtpl:288
 	p.streamerrorMessagesTable(qw422016)
-	//line report/report.qtpl:63
+//line report/report.qtpl:288
+	qw422016.N().S(`
+		`)
+//line report/report.qtpl:289
+	if len(p.AssertionFailures) > 0 {
+//line report/report.qtpl:289
+		qw422016.N().S(`
+			`)
+//line report/report.qtpl:290
+		p.streamassertionsTable(qw422016)
+//line report/report.qtpl:290
+		qw422016.N().S(`
+		`)
+//line report/report.qtpl:291
+	}
+//line report/report.qtpl:291
+	qw422016.N().S(`
+		`)
+//line report/report.qtpl:292
+	if len(p.DisconnectReasons) > 0 {
+//line report/report.qtpl:292
+		qw422016.N().S(`
+			`)
+//line report/report.qtpl:293
+		p.streamdisconnectsTable(qw422016)
+//line report/report.qtpl:293
+		qw422016.N().S(`
+		`)
+//line report/report.qtpl:294
+	}
+//line report/report.qtpl:294
+	qw422016.N().S(`
+		`)
+//line report/report.qtpl:295
+	if len(p.FlowSteps) > 0 {
+//line report/report.qtpl:295
+		qw422016.N().S(`
+			`)
+//line report/report.qtpl:296
+		p.streamflowTable(qw422016)
+//line report/report.qtpl:296
+		qw422016.N().S(`
+		`)
+//line report/report.qtpl:297
+	}
+//line report/report.qtpl:297
+	qw422016.N().S(`
+		`)
+//line report/report.qtpl:298
+	if len(p.IPTraffic) > 0 {
+//line report/report.qtpl:298
+		qw422016.N().S(`
+			`)
+//line report/report.qtpl:299
+		p.streamipTrafficTable(qw422016)
+//line report/report.qtpl:299
+		qw422016.N().S(`
+		`)
+//line report/report.qtpl:300
+	}
+//line report/report.qtpl:300
+	qw422016.N().S(`
+		`)
+//line report/report.qtpl:301
+	if len(p.Steps) > 0 {
+//line report/report.qtpl:301
+		qw422016.N().S(`
+			`)
+//line report/report.qtpl:302
+		p.streamcapacityTable(qw422016)
+//line report/report.qtpl:302
+		qw422016.N().S(`
+		`)
+//line report/report.qtpl:303
+	}
+//line report/report.qtpl:303
+	qw422016.N().S(`
+		`)
+//line report/report.qtpl:304
+	if len(p.Decisions) > 0 {
+//line report/report.qtpl:304
+		qw422016.N().S(`
+			`)
+//line report/report.qtpl:305
+		p.streamdecisionsTable(qw422016)
+//line report/report.qtpl:305
+		qw422016.N().S(`
+		`)
+//line report/report.qtpl:306
+	}
+//line report/report.qtpl:306
 	qw422016.N().S(`
 	</body>
 </html>
 `)
-//line report/report.qtpl:66
+//line report/report.qtpl:309
 }
 
-//line report/report.qtpl:66
+//line report/report.qtpl:309
 func WritePrintPage(qq422016 qtio422016.Writer, p *Page) {
-	//line report/report.qtpl:66
+//line report/report.qtpl:309
 	qw422016 := qt422016.AcquireWriter(qq422016)
-	//line report/report.qtpl:66
+//line report/report.qtpl:309
 	StreamPrintPage(qw422016, p)
-	//line report/report.qtpl:66
+//line report/report.qtpl:309
 	qt422016.ReleaseWriter(qw422016)
-//line report/report.qtpl:66
+//line report/report.qtpl:309
 }
 
-//line report/report.qtpl:66
+//line report/report.qtpl:309
 func PrintPage(p *Page) string {
-	//line report/report.qtpl:66
+//line report/report.qtpl:309
 	qb422016 := qt422016.AcquireByteBuffer()
-	//line report/report.qtpl:66
+//line report/report.qtpl:309
 	WritePrintPage(qb422016, p)
-	//line report/report.qtpl:66
+//line report/report.qtpl:309
 	qs422016 := string(qb422016.B)
-	//line report/report.qtpl:66
+//line report/report.qtpl:309
 	qt422016.ReleaseByteBuffer(qb422016)
-	//line report/report.qtpl:66
+//line report/report.qtpl:309
 	return qs422016
-//line report/report.qtpl:66
+//line report/report.qtpl:309
 }
 
-//line report/report.qtpl:68
+//line report/report.qtpl:311
 func (p *Page) streamsimpleChart(qw422016 *qt422016.Writer, title string, fn seriesFunc) {
-	//line report/report.qtpl:68
+//line report/report.qtpl:311
 	qw422016.N().S(`
 	<script>
 	$(function () {
     			$('#`)
-	//line report/report.qtpl:71
+//line report/report.qtpl:314
 	qw422016.N().S(title)
-	//line report/report.qtpl:71
+//line report/report.qtpl:314
 	qw422016.N().S(`').highcharts({
 					title: {
 						text: '`)
-	//line report/report.qtpl:73
+//line report/report.qtpl:316
 	qw422016.N().S(strings.Title(title))
-	//line report/report.qtpl:73
+//line report/report.qtpl:316
 	qw422016.N().S(`',
 						x: -20 //center
 					},
 					xAxis: {
 						type: 'linear',
+						plotLines: `)
+//line report/report.qtpl:321
+	p.streamdecisionPlotLines(qw422016)
+//line report/report.qtpl:321
+	qw422016.N().S(`
 					},
 					legend: {
 						layout: 'vertical',
@@ -251,76 +616,176 @@ func (p *Page) streamsimpleChart(qw422016 *qt422016.Writer, title string, fn ser
 						series: {
 							pointStart: 0,
 							pointInterval: `)
-	//line report/report.qtpl:88
+//line report/report.qtpl:332
 	qw422016.N().FPrec(p.Interval, 2)
-	//line report/report.qtpl:88
+//line report/report.qtpl:332
 	qw422016.N().S(`,
 						}
 					},
 					series: `)
-	//line report/report.qtpl:91
+//line report/report.qtpl:335
 	qw422016.N().S(fn())
-	//line report/report.qtpl:91
+//line report/report.qtpl:335
 	qw422016.N().S(`
 				});
     		});
     </script>
    	<div id="`)
-	//line report/report.qtpl:95
+//line report/report.qtpl:339
 	qw422016.N().S(title)
-	//line report/report.qtpl:95
+//line report/report.qtpl:339
 	qw422016.N().S(`" style="min-width: 310px; height: 400px; margin: 0 auto"></div>
 `)
-//line report/report.qtpl:96
+//line report/report.qtpl:340
 }
 
-//line report/report.qtpl:96
+//line report/report.qtpl:340
 func (p *Page) writesimpleChart(qq422016 qtio422016.Writer, title string, fn seriesFunc) {
-	//line report/report.qtpl:96
+//line report/report.qtpl:340
 	qw422016 := qt422016.AcquireWriter(qq422016)
-	//line report/report.qtpl:96
+//line report/report.qtpl:340
 	p.streamsimpleChart(qw422016, title, fn)
-	//line report/report.qtpl:96
+//line report/report.qtpl:340
 	qt422016.ReleaseWriter(qw422016)
-//line report/report.qtpl:96
+//line report/report.qtpl:340
 }
 
-//line report/report.qtpl:96
+//line report/report.qtpl:340
 func (p *Page) simpleChart(title string, fn seriesFunc) string {
-	//line report/report.qtpl:96
+//line report/report.qtpl:340
 	qb422016 := qt422016.AcquireByteBuffer()
-	//line report/report.qtpl:96
+//line report/report.qtpl:340
 	p.writesimpleChart(qb422016, title, fn)
-	//line report/report.qtpl:96
+//line report/report.qtpl:340
+	qs422016 := string(qb422016.B)
+//line report/report.qtpl:340
+	qt422016.ReleaseByteBuffer(qb422016)
+//line report/report.qtpl:340
+	return qs422016
+//line report/report.qtpl:340
+}
+
+//line report/report.qtpl:342
+func (p *Page) streamstackedChart(qw422016 *qt422016.Writer, title string, fn seriesFunc) {
+//line report/report.qtpl:342
+	qw422016.N().S(`
+	<script>
+	$(function () {
+    			$('#`)
+//line report/report.qtpl:345
+	qw422016.N().S(title)
+//line report/report.qtpl:345
+	qw422016.N().S(`').highcharts({
+					chart: {
+						type: 'area'
+					},
+					title: {
+						text: '`)
+//line report/report.qtpl:350
+	qw422016.N().S(strings.Title(title))
+//line report/report.qtpl:350
+	qw422016.N().S(`',
+						x: -20 //center
+					},
+					xAxis: {
+						type: 'linear',
+						plotLines: `)
+//line report/report.qtpl:355
+	p.streamdecisionPlotLines(qw422016)
+//line report/report.qtpl:355
+	qw422016.N().S(`
+					},
+					tooltip: {
+						shared: true
+					},
+					legend: {
+						layout: 'vertical',
+						align: 'right',
+						verticalAlign: 'middle',
+						borderWidth: 0
+					},
+					plotOptions: {
+						area: {
+							stacking: 'normal'
+						},
+						series: {
+							pointStart: 0,
+							pointInterval: `)
+//line report/report.qtpl:372
+	qw422016.N().FPrec(p.Interval, 2)
+//line report/report.qtpl:372
+	qw422016.N().S(`,
+						}
+					},
+					series: `)
+//line report/report.qtpl:375
+	qw422016.N().S(fn())
+//line report/report.qtpl:375
+	qw422016.N().S(`
+				});
+    		});
+    </script>
+   	<div id="`)
+//line report/report.qtpl:379
+	qw422016.N().S(title)
+//line report/report.qtpl:379
+	qw422016.N().S(`" style="min-width: 310px; height: 400px; margin: 0 auto"></div>
+`)
+//line report/report.qtpl:380
+}
+
+//line report/report.qtpl:380
+func (p *Page) writestackedChart(qq422016 qtio422016.Writer, title string, fn seriesFunc) {
+//line report/report.qtpl:380
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line report/report.qtpl:380
+	p.streamstackedChart(qw422016, title, fn)
+//line report/report.qtpl:380
+	qt422016.ReleaseWriter(qw422016)
+//line report/report.qtpl:380
+}
+
+//line report/report.qtpl:380
+func (p *Page) stackedChart(title string, fn seriesFunc) string {
+//line report/report.qtpl:380
+	qb422016 := qt422016.AcquireByteBuffer()
+//line report/report.qtpl:380
+	p.writestackedChart(qb422016, title, fn)
+//line report/report.qtpl:380
 	qs422016 := string(qb422016.B)
-	//line report/report.qtpl:96
+//line report/report.qtpl:380
 	qt422016.ReleaseByteBuffer(qb422016)
-	//line report/report.qtpl:96
+//line report/report.qtpl:380
 	return qs422016
-//line report/report.qtpl:96
+//line report/report.qtpl:380
 }
 
-//line report/report.qtpl:98
+//line report/report.qtpl:382
 func (p *Page) streambytesChart(qw422016 *qt422016.Writer, title string, fn seriesFunc) {
-	//line report/report.qtpl:98
+//line report/report.qtpl:382
 	qw422016.N().S(`
 	<script>
 	$(function () {
     			$('#`)
-	//line report/report.qtpl:101
+//line report/report.qtpl:385
 	qw422016.N().S(title)
-	//line report/report.qtpl:101
+//line report/report.qtpl:385
 	qw422016.N().S(`').highcharts({
 					title: {
 						text: '`)
-	//line report/report.qtpl:103
+//line report/report.qtpl:387
 	qw422016.N().S(strings.Title(title))
-	//line report/report.qtpl:103
+//line report/report.qtpl:387
 	qw422016.N().S(`',
 						x: -20 //center
 					},
 					xAxis: {
 						type: 'linear',
+						plotLines: `)
+//line report/report.qtpl:392
+	p.streamdecisionPlotLines(qw422016)
+//line report/report.qtpl:392
+	qw422016.N().S(`
 					},
 					yAxis: {
 						labels: {
@@ -342,65 +807,65 @@ func (p *Page) streambytesChart(qw422016 *qt422016.Writer, title string, fn seri
 						series: {
 							pointStart: 0,
 							pointInterval: `)
-	//line report/report.qtpl:128
+//line report/report.qtpl:413
 	qw422016.N().FPrec(p.Interval, 2)
-	//line report/report.qtpl:128
+//line report/report.qtpl:413
 	qw422016.N().S(`,
 						}
 					},
 					series: `)
-	//line report/report.qtpl:131
+//line report/report.qtpl:416
 	qw422016.N().S(fn())
-	//line report/report.qtpl:131
+//line report/report.qtpl:416
 	qw422016.N().S(`
 				});
     		});
     </script>
    	<div id="`)
-	//line report/report.qtpl:135
+//line report/report.qtpl:420
 	qw422016.N().S(title)
-	//line report/report.qtpl:135
+//line report/report.qtpl:420
 	qw422016.N().S(`" style="min-width: 310px; height: 400px; margin: 0 auto"></div>
 `)
-//line report/report.qtpl:136
+//line report/report.qtpl:421
 }
 
-//line report/report.qtpl:136
+//line report/report.qtpl:421
 func (p *Page) writebytesChart(qq422016 qtio422016.Writer, title string, fn seriesFunc) {
-	//line report/report.qtpl:136
+//line report/report.qtpl:421
 	qw422016 := qt422016.AcquireWriter(qq422016)
-	//line report/report.qtpl:136
+//line report/report.qtpl:421
 	p.streambytesChart(qw422016, title, fn)
-	//line report/report.qtpl:136
+//line report/report.qtpl:421
 	qt422016.ReleaseWriter(qw422016)
-//line report/report.qtpl:136
+//line report/report.qtpl:421
 }
 
-//line report/report.qtpl:136
+//line report/report.qtpl:421
 func (p *Page) bytesChart(title string, fn seriesFunc) string {
-	//line report/report.qtpl:136
+//line report/report.qtpl:421
 	qb422016 := qt422016.AcquireByteBuffer()
-	//line report/report.qtpl:136
+//line report/report.qtpl:421
 	p.writebytesChart(qb422016, title, fn)
-	//line report/report.qtpl:136
+//line report/report.qtpl:421
 	qs422016 := string(qb422016.B)
-	//line report/report.qtpl:136
+//line report/report.qtpl:421
 	qt422016.ReleaseByteBuffer(qb422016)
-	//line report/report.qtpl:136
+//line report/report.qtpl:421
 	return qs422016
-//line report/report.qtpl:136
+//line report/report.qtpl:421
 }
 
-//line report/report.qtpl:138
+//line report/report.qtpl:423
 func (p *Page) streampieChart(qw422016 *qt422016.Writer, title string, fn seriesFunc) {
-	//line report/report.qtpl:138
+//line report/report.qtpl:423
 	qw422016.N().S(`
 	<script>
 	$(function () {
     			$('#`)
-	//line report/report.qtpl:141
+//line report/report.qtpl:426
 	qw422016.N().S(title)
-	//line report/report.qtpl:141
+//line report/report.qtpl:426
 	qw422016.N().S(`').highcharts({
 					chart: {
 						plotBackgroundColor: null,
@@ -410,9 +875,9 @@ func (p *Page) streampieChart(qw422016 *qt422016.Writer, title string, fn series
 					},
 					title: {
 						text: '`)
-	//line report/report.qtpl:149
+//line report/report.qtpl:434
 	qw422016.N().S(strings.Title(title))
-	//line report/report.qtpl:149
+//line report/report.qtpl:434
 	qw422016.N().S(`',
 					},
 					 tooltip: {
@@ -429,344 +894,884 @@ func (p *Page) streampieChart(qw422016 *qt422016.Writer, title string, fn series
 						}
 					},
 					series: `)
-	//line report/report.qtpl:164
+//line report/report.qtpl:449
 	qw422016.N().S(fn())
-	//line report/report.qtpl:164
+//line report/report.qtpl:449
 	qw422016.N().S(`
 				});
     		});
     </script>
    	<div id="`)
-	//line report/report.qtpl:168
+//line report/report.qtpl:453
 	qw422016.N().S(title)
-	//line report/report.qtpl:168
+//line report/report.qtpl:453
 	qw422016.N().S(`" style = "float: left; width:50%; height: 400px;"></div>
 `)
-//line report/report.qtpl:169
+//line report/report.qtpl:454
 }
 
-//line report/report.qtpl:169
+//line report/report.qtpl:454
 func (p *Page) writepieChart(qq422016 qtio422016.Writer, title string, fn seriesFunc) {
-	//line report/report.qtpl:169
+//line report/report.qtpl:454
 	qw422016 := qt422016.AcquireWriter(qq422016)
-	//line report/report.qtpl:169
+//line report/report.qtpl:454
 	p.streampieChart(qw422016, title, fn)
-	//line report/report.qtpl:169
+//line report/report.qtpl:454
 	qt422016.ReleaseWriter(qw422016)
-//line report/report.qtpl:169
+//line report/report.qtpl:454
 }
 
-//line report/report.qtpl:169
+//line report/report.qtpl:454
 func (p *Page) pieChart(title string, fn seriesFunc) string {
-	//line report/report.qtpl:169
+//line report/report.qtpl:454
 	qb422016 := qt422016.AcquireByteBuffer()
-	//line report/report.qtpl:169
+//line report/report.qtpl:454
 	p.writepieChart(qb422016, title, fn)
-	//line report/report.qtpl:169
+//line report/report.qtpl:454
 	qs422016 := string(qb422016.B)
-	//line report/report.qtpl:169
+//line report/report.qtpl:454
 	qt422016.ReleaseByteBuffer(qb422016)
-	//line report/report.qtpl:169
+//line report/report.qtpl:454
 	return qs422016
-//line report/report.qtpl:169
+//line report/report.qtpl:454
 }
 
-//line report/report.qtpl:171
+//line report/report.qtpl:456
 func (p *Page) streamconnectionSeries(qw422016 *qt422016.Writer) {
-	//line report/report.qtpl:171
+//line report/report.qtpl:456
 	qw422016.N().S(`
 	[{
 		name: 'Connections',
 		data: [`)
-	//line report/report.qtpl:174
+//line report/report.qtpl:459
 	qw422016.N().S(uint64SliceToString(p.Connections))
-	//line report/report.qtpl:174
+//line report/report.qtpl:459
 	qw422016.N().S(`]
-	}]
+	},{
+		name: 'New-connections-per-second',
+		data: [`)
+//line report/report.qtpl:462
+	qw422016.N().S(float64SliceToString(rate(p.NewConnections, p.Interval)))
+//line report/report.qtpl:462
+	qw422016.N().S(`]
+	}
+	`)
+//line report/report.qtpl:464
+	if p.EventStream {
+//line report/report.qtpl:464
+		qw422016.N().S(`
+	,{
+		name: 'Stream drops',
+		data: [`)
+//line report/report.qtpl:467
+		qw422016.N().S(float64SliceToString(rate(p.EventStreamDrops, p.Interval)))
+//line report/report.qtpl:467
+		qw422016.N().S(`]
+	}
+	`)
+//line report/report.qtpl:469
+	}
+//line report/report.qtpl:469
+	qw422016.N().S(`
+	]
 `)
-//line report/report.qtpl:176
+//line report/report.qtpl:471
 }
 
-//line report/report.qtpl:176
+//line report/report.qtpl:471
 func (p *Page) writeconnectionSeries(qq422016 qtio422016.Writer) {
-	//line report/report.qtpl:176
+//line report/report.qtpl:471
 	qw422016 := qt422016.AcquireWriter(qq422016)
-	//line report/report.qtpl:176
+//line report/report.qtpl:471
 	p.streamconnectionSeries(qw422016)
-	//line report/report.qtpl:176
+//line report/report.qtpl:471
 	qt422016.ReleaseWriter(qw422016)
-//line report/report.qtpl:176
+//line report/report.qtpl:471
 }
 
-//line report/report.qtpl:176
+//line report/report.qtpl:471
 func (p *Page) connectionSeries() string {
-	//line report/report.qtpl:176
+//line report/report.qtpl:471
 	qb422016 := qt422016.AcquireByteBuffer()
-	//line report/report.qtpl:176
+//line report/report.qtpl:471
 	p.writeconnectionSeries(qb422016)
-	//line report/report.qtpl:176
+//line report/report.qtpl:471
 	qs422016 := string(qb422016.B)
-	//line report/report.qtpl:176
+//line report/report.qtpl:471
 	qt422016.ReleaseByteBuffer(qb422016)
-	//line report/report.qtpl:176
+//line report/report.qtpl:471
 	return qs422016
-//line report/report.qtpl:176
+//line report/report.qtpl:471
 }
 
-//line report/report.qtpl:178
-func (p *Page) streamqpsSeries(qw422016 *qt422016.Writer) {
-	//line report/report.qtpl:178
+//line report/report.qtpl:473
+func (p *Page) streamreuseSeries(qw422016 *qt422016.Writer) {
+//line report/report.qtpl:473
 	qw422016.N().S(`
 	[{
-		name: 'Load average',
-		data: [`)
-	//line report/report.qtpl:181
-	qw422016.N().S(uint64SliceToString(p.Qps))
-	//line report/report.qtpl:181
-	qw422016.N().S(`]
-	},
-	{
-		name: 'Req-per-second',
+		name: 'Reuse ratio, %',
 		data: [`)
-	//line report/report.qtpl:185
-	qw422016.N().S(float64SliceToString(rate(p.RequestSum, p.Interval)))
-	//line report/report.qtpl:185
+//line report/report.qtpl:476
+	qw422016.N().S(float64SliceToString(reuseRatio(p.RequestSum, p.NewConnections, p.Interval)))
+//line report/report.qtpl:476
 	qw422016.N().S(`]
 	}]
 `)
-//line report/report.qtpl:187
+//line report/report.qtpl:478
 }
 
-//line report/report.qtpl:187
-func (p *Page) writeqpsSeries(qq422016 qtio422016.Writer) {
-	//line report/report.qtpl:187
+//line report/report.qtpl:478
+func (p *Page) writereuseSeries(qq422016 qtio422016.Writer) {
+//line report/report.qtpl:478
 	qw422016 := qt422016.AcquireWriter(qq422016)
-	//line report/report.qtpl:187
-	p.streamqpsSeries(qw422016)
-	//line report/report.qtpl:187
+//line report/report.qtpl:478
+	p.streamreuseSeries(qw422016)
+//line report/report.qtpl:478
 	qt422016.ReleaseWriter(qw422016)
-//line report/report.qtpl:187
+//line report/report.qtpl:478
 }
 
-//line report/report.qtpl:187
-func (p *Page) qpsSeries() string {
-	//line report/report.qtpl:187
+//line report/report.qtpl:478
+func (p *Page) reuseSeries() string {
+//line report/report.qtpl:478
 	qb422016 := qt422016.AcquireByteBuffer()
-	//line report/report.qtpl:187
-	p.writeqpsSeries(qb422016)
-	//line report/report.qtpl:187
+//line report/report.qtpl:478
+	p.writereuseSeries(qb422016)
+//line report/report.qtpl:478
 	qs422016 := string(qb422016.B)
-	//line report/report.qtpl:187
+//line report/report.qtpl:478
 	qt422016.ReleaseByteBuffer(qb422016)
-	//line report/report.qtpl:187
+//line report/report.qtpl:478
 	return qs422016
-//line report/report.qtpl:187
+//line report/report.qtpl:478
 }
 
-//line report/report.qtpl:189
-func (p *Page) streamerrorSeries(qw422016 *qt422016.Writer) {
-	//line report/report.qtpl:189
+//line report/report.qtpl:480
+func (p *Page) streamqpsSeries(qw422016 *qt422016.Writer) {
+//line report/report.qtpl:480
 	qw422016.N().S(`
 	[{
-		name: 'Errors',
+		name: 'Load average',
 		data: [`)
-	//line report/report.qtpl:192
-	qw422016.N().S(float64SliceToString(rate(p.Errors, p.Interval)))
-	//line report/report.qtpl:192
+//line report/report.qtpl:483
+	qw422016.N().S(uint64SliceToString(p.Qps))
+//line report/report.qtpl:483
 	qw422016.N().S(`]
-	},{
-		name: 'Timeouts',
+	},
+	{
+		name: 'Req-per-second',
 		data: [`)
-	//line report/report.qtpl:195
-	qw422016.N().S(float64SliceToString(rate(p.Timeouts, p.Interval)))
-	//line report/report.qtpl:195
+//line report/report.qtpl:487
+	qw422016.N().S(float64SliceToString(rate(p.RequestSum, p.Interval)))
+//line report/report.qtpl:487
 	qw422016.N().S(`]
-	}]
-`)
-//line report/report.qtpl:197
-}
-
-//line report/report.qtpl:197
-func (p *Page) writeerrorSeries(qq422016 qtio422016.Writer) {
-	//line report/report.qtpl:197
-	qw422016 := qt422016.AcquireWriter(qq422016)
-	//line report/report.qtpl:197
-	p.streamerrorSeries(qw422016)
-	//line report/report.qtpl:197
-	qt422016.ReleaseWriter(qw422016)
-//line report/report.qtpl:197
-}
-
-//line report/report.qtpl:197
-func (p *Page) errorSeries() string {
-	//line report/report.qtpl:197
-	qb422016 := qt422016.AcquireByteBuffer()
-	//line report/report.qtpl:197
-	p.writeerrorSeries(qb422016)
-	//line report/report.qtpl:197
-	qs422016 := string(qb422016.B)
-	//line report/report.qtpl:197
-	qt422016.ReleaseByteBuffer(qb422016)
-	//line report/report.qtpl:197
+	}
+	`)
+//line report/report.qtpl:489
+	if p.Sessions {
+//line report/report.qtpl:489
+		qw422016.N().S(`
+	,{
+		name: 'Logins-per-second',
+		data: [`)
+//line report/report.qtpl:492
+		qw422016.N().S(float64SliceToString(rate(p.Logins, p.Interval)))
+//line report/report.qtpl:492
+		qw422016.N().S(`]
+	},{
+		name: 'Session-resets-per-second',
+		data: [`)
+//line report/report.qtpl:495
+		qw422016.N().S(float64SliceToString(rate(p.SessionResets, p.Interval)))
+//line report/report.qtpl:495
+		qw422016.N().S(`]
+	}
+	`)
+//line report/report.qtpl:497
+	}
+//line report/report.qtpl:497
+	qw422016.N().S(`
+	`)
+//line report/report.qtpl:498
+	if len(p.Redirects) > 0 {
+//line report/report.qtpl:498
+		qw422016.N().S(`
+	,{
+		name: 'Redirects-per-second',
+		data: [`)
+//line report/report.qtpl:501
+		qw422016.N().S(float64SliceToString(rate(p.Redirects, p.Interval)))
+//line report/report.qtpl:501
+		qw422016.N().S(`]
+	}
+	`)
+//line report/report.qtpl:503
+	}
+//line report/report.qtpl:503
+	qw422016.N().S(`
+	`)
+//line report/report.qtpl:504
+	if p.EventStream {
+//line report/report.qtpl:504
+		qw422016.N().S(`
+	,{
+		name: 'Events-per-second',
+		data: [`)
+//line report/report.qtpl:507
+		qw422016.N().S(float64SliceToString(rate(p.Events, p.Interval)))
+//line report/report.qtpl:507
+		qw422016.N().S(`]
+	}
+	`)
+//line report/report.qtpl:509
+	}
+//line report/report.qtpl:509
+	qw422016.N().S(`
+	]
+`)
+//line report/report.qtpl:511
+}
+
+//line report/report.qtpl:511
+func (p *Page) writeqpsSeries(qq422016 qtio422016.Writer) {
+//line report/report.qtpl:511
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line report/report.qtpl:511
+	p.streamqpsSeries(qw422016)
+//line report/report.qtpl:511
+	qt422016.ReleaseWriter(qw422016)
+//line report/report.qtpl:511
+}
+
+//line report/report.qtpl:511
+func (p *Page) qpsSeries() string {
+//line report/report.qtpl:511
+	qb422016 := qt422016.AcquireByteBuffer()
+//line report/report.qtpl:511
+	p.writeqpsSeries(qb422016)
+//line report/report.qtpl:511
+	qs422016 := string(qb422016.B)
+//line report/report.qtpl:511
+	qt422016.ReleaseByteBuffer(qb422016)
+//line report/report.qtpl:511
+	return qs422016
+//line report/report.qtpl:511
+}
+
+//line report/report.qtpl:513
+func (p *Page) streamerrorSeries(qw422016 *qt422016.Writer) {
+//line report/report.qtpl:513
+	qw422016.N().S(`
+	[{
+		name: 'Errors',
+		data: [`)
+//line report/report.qtpl:516
+	qw422016.N().S(float64SliceToString(rate(p.Errors, p.Interval)))
+//line report/report.qtpl:516
+	qw422016.N().S(`]
+	},{
+		name: 'Timeouts',
+		data: [`)
+//line report/report.qtpl:519
+	qw422016.N().S(float64SliceToString(rate(p.Timeouts, p.Interval)))
+//line report/report.qtpl:519
+	qw422016.N().S(`]
+	},{
+		name: 'Handshake errors',
+		data: [`)
+//line report/report.qtpl:522
+	qw422016.N().S(float64SliceToString(rate(p.HandshakeErrors, p.Interval)))
+//line report/report.qtpl:522
+	qw422016.N().S(`]
+	},{
+		name: 'Ports exhausted',
+		data: [`)
+//line report/report.qtpl:525
+	qw422016.N().S(float64SliceToString(rate(p.PortsExhausted, p.Interval)))
+//line report/report.qtpl:525
+	qw422016.N().S(`]
+	},{
+		name: 'Proxy errors',
+		data: [`)
+//line report/report.qtpl:528
+	qw422016.N().S(float64SliceToString(rate(p.ProxyErrors, p.Interval)))
+//line report/report.qtpl:528
+	qw422016.N().S(`]
+	}
+	`)
+//line report/report.qtpl:530
+	if p.Auth {
+//line report/report.qtpl:530
+		qw422016.N().S(`
+	,{
+		name: 'Auth failures',
+		data: [`)
+//line report/report.qtpl:533
+		qw422016.N().S(float64SliceToString(rate(p.AuthFailures, p.Interval)))
+//line report/report.qtpl:533
+		qw422016.N().S(`]
+	},{
+		name: 'Token errors',
+		data: [`)
+//line report/report.qtpl:536
+		qw422016.N().S(float64SliceToString(rate(p.TokenErrors, p.Interval)))
+//line report/report.qtpl:536
+		qw422016.N().S(`]
+	}
+	`)
+//line report/report.qtpl:538
+	}
+//line report/report.qtpl:538
+	qw422016.N().S(`
+	`)
+//line report/report.qtpl:539
+	if p.Script {
+//line report/report.qtpl:539
+		qw422016.N().S(`
+	,{
+		name: 'Script errors',
+		data: [`)
+//line report/report.qtpl:542
+		qw422016.N().S(float64SliceToString(rate(p.ScriptErrors, p.Interval)))
+//line report/report.qtpl:542
+		qw422016.N().S(`]
+	}
+	`)
+//line report/report.qtpl:544
+	}
+//line report/report.qtpl:544
+	qw422016.N().S(`
+	`)
+//line report/report.qtpl:545
+	if p.Sessions {
+//line report/report.qtpl:545
+		qw422016.N().S(`
+	,{
+		name: 'Login errors',
+		data: [`)
+//line report/report.qtpl:548
+		qw422016.N().S(float64SliceToString(rate(p.LoginErrors, p.Interval)))
+//line report/report.qtpl:548
+		qw422016.N().S(`]
+	}
+	`)
+//line report/report.qtpl:550
+	}
+//line report/report.qtpl:550
+	qw422016.N().S(`
+	`)
+//line report/report.qtpl:551
+	if p.Websocket {
+//line report/report.qtpl:551
+		qw422016.N().S(`
+	,{
+		name: 'Disconnects',
+		data: [`)
+//line report/report.qtpl:554
+		qw422016.N().S(float64SliceToString(rate(p.Disconnects, p.Interval)))
+//line report/report.qtpl:554
+		qw422016.N().S(`]
+	}
+	`)
+//line report/report.qtpl:556
+	}
+//line report/report.qtpl:556
+	qw422016.N().S(`
+	]
+`)
+//line report/report.qtpl:558
+}
+
+//line report/report.qtpl:558
+func (p *Page) writeerrorSeries(qq422016 qtio422016.Writer) {
+//line report/report.qtpl:558
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line report/report.qtpl:558
+	p.streamerrorSeries(qw422016)
+//line report/report.qtpl:558
+	qt422016.ReleaseWriter(qw422016)
+//line report/report.qtpl:558
+}
+
+//line report/report.qtpl:558
+func (p *Page) errorSeries() string {
+//line report/report.qtpl:558
+	qb422016 := qt422016.AcquireByteBuffer()
+//line report/report.qtpl:558
+	p.writeerrorSeries(qb422016)
+//line report/report.qtpl:558
+	qs422016 := string(qb422016.B)
+//line report/report.qtpl:558
+	qt422016.ReleaseByteBuffer(qb422016)
+//line report/report.qtpl:558
+	return qs422016
+//line report/report.qtpl:558
+}
+
+//line report/report.qtpl:560
+func (p *Page) streamhandshakeSeries(qw422016 *qt422016.Writer) {
+//line report/report.qtpl:560
+	qw422016.N().S(`
+	[{
+		name: 'Handshakes-per-second',
+		data: [`)
+//line report/report.qtpl:563
+	qw422016.N().S(float64SliceToString(rate(p.Handshakes, p.Interval)))
+//line report/report.qtpl:563
+	qw422016.N().S(`]
+	},{
+		name: 'Resumed-per-second',
+		data: [`)
+//line report/report.qtpl:566
+	qw422016.N().S(float64SliceToString(rate(p.ResumedHandshakes, p.Interval)))
+//line report/report.qtpl:566
+	qw422016.N().S(`]
+	}]
+`)
+//line report/report.qtpl:568
+}
+
+//line report/report.qtpl:568
+func (p *Page) writehandshakeSeries(qq422016 qtio422016.Writer) {
+//line report/report.qtpl:568
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line report/report.qtpl:568
+	p.streamhandshakeSeries(qw422016)
+//line report/report.qtpl:568
+	qt422016.ReleaseWriter(qw422016)
+//line report/report.qtpl:568
+}
+
+//line report/report.qtpl:568
+func (p *Page) handshakeSeries() string {
+//line report/report.qtpl:568
+	qb422016 := qt422016.AcquireByteBuffer()
+//line report/report.qtpl:568
+	p.writehandshakeSeries(qb422016)
+//line report/report.qtpl:568
+	qs422016 := string(qb422016.B)
+//line report/report.qtpl:568
+	qt422016.ReleaseByteBuffer(qb422016)
+//line report/report.qtpl:568
+	return qs422016
+//line report/report.qtpl:568
+}
+
+//line report/report.qtpl:570
+func (p *Page) streamstreamSeries(qw422016 *qt422016.Writer) {
+//line report/report.qtpl:570
+	qw422016.N().S(`
+	[{
+		name: 'Open streams',
+		data: [`)
+//line report/report.qtpl:573
+	qw422016.N().S(uint64SliceToString(p.Streams))
+//line report/report.qtpl:573
+	qw422016.N().S(`]
+	},{
+		name: 'Streams-per-second',
+		data: [`)
+//line report/report.qtpl:576
+	qw422016.N().S(float64SliceToString(rate(p.StreamsSum, p.Interval)))
+//line report/report.qtpl:576
+	qw422016.N().S(`]
+	},{
+		name: 'Stream errors',
+		data: [`)
+//line report/report.qtpl:579
+	qw422016.N().S(float64SliceToString(rate(p.StreamErrors, p.Interval)))
+//line report/report.qtpl:579
+	qw422016.N().S(`]
+	}]
+`)
+//line report/report.qtpl:581
+}
+
+//line report/report.qtpl:581
+func (p *Page) writestreamSeries(qq422016 qtio422016.Writer) {
+//line report/report.qtpl:581
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line report/report.qtpl:581
+	p.streamstreamSeries(qw422016)
+//line report/report.qtpl:581
+	qt422016.ReleaseWriter(qw422016)
+//line report/report.qtpl:581
+}
+
+//line report/report.qtpl:581
+func (p *Page) streamSeries() string {
+//line report/report.qtpl:581
+	qb422016 := qt422016.AcquireByteBuffer()
+//line report/report.qtpl:581
+	p.writestreamSeries(qb422016)
+//line report/report.qtpl:581
+	qs422016 := string(qb422016.B)
+//line report/report.qtpl:581
+	qt422016.ReleaseByteBuffer(qb422016)
+//line report/report.qtpl:581
 	return qs422016
-//line report/report.qtpl:197
+//line report/report.qtpl:581
 }
 
-//line report/report.qtpl:200
+//line report/report.qtpl:584
 func (p *Page) streamdurationSeries(qw422016 *qt422016.Writer) {
-	//line report/report.qtpl:200
+//line report/report.qtpl:584
+	qw422016.N().S(`[`)
+//line report/report.qtpl:586
+	streamquantileSeries(qw422016, "", p.RequestDuration)
+//line report/report.qtpl:587
+	if len(p.ConnectDuration) > 0 {
+//line report/report.qtpl:588
+		if len(p.RequestDuration) > 0 {
+//line report/report.qtpl:588
+			qw422016.N().S(`,`)
+//line report/report.qtpl:588
+		}
+//line report/report.qtpl:589
+		streamquantileSeries(qw422016, "connect ", p.ConnectDuration)
+//line report/report.qtpl:590
+	}
+//line report/report.qtpl:591
+	if len(p.HandshakeDuration) > 0 {
+//line report/report.qtpl:592
+		if len(p.RequestDuration) > 0 || len(p.ConnectDuration) > 0 {
+//line report/report.qtpl:592
+			qw422016.N().S(`,`)
+//line report/report.qtpl:592
+		}
+//line report/report.qtpl:593
+		streamquantileSeries(qw422016, "handshake ", p.HandshakeDuration)
+//line report/report.qtpl:594
+	}
+//line report/report.qtpl:595
+	if len(p.FirstHopDuration) > 0 {
+//line report/report.qtpl:596
+		if len(p.RequestDuration) > 0 || len(p.ConnectDuration) > 0 || len(p.HandshakeDuration) > 0 {
+//line report/report.qtpl:596
+			qw422016.N().S(`,`)
+//line report/report.qtpl:596
+		}
+//line report/report.qtpl:597
+		streamquantileSeries(qw422016, "first hop ", p.FirstHopDuration)
+//line report/report.qtpl:598
+	}
+//line report/report.qtpl:598
+	qw422016.N().S(`]`)
+//line report/report.qtpl:600
+}
+
+//line report/report.qtpl:600
+func (p *Page) writedurationSeries(qq422016 qtio422016.Writer) {
+//line report/report.qtpl:600
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line report/report.qtpl:600
+	p.streamdurationSeries(qw422016)
+//line report/report.qtpl:600
+	qt422016.ReleaseWriter(qw422016)
+//line report/report.qtpl:600
+}
+
+//line report/report.qtpl:600
+func (p *Page) durationSeries() string {
+//line report/report.qtpl:600
+	qb422016 := qt422016.AcquireByteBuffer()
+//line report/report.qtpl:600
+	p.writedurationSeries(qb422016)
+//line report/report.qtpl:600
+	qs422016 := string(qb422016.B)
+//line report/report.qtpl:600
+	qt422016.ReleaseByteBuffer(qb422016)
+//line report/report.qtpl:600
+	return qs422016
+//line report/report.qtpl:600
+}
+
+//line report/report.qtpl:604
+func (p *Page) streamphaseSeries(qw422016 *qt422016.Writer) {
+//line report/report.qtpl:604
 	qw422016.N().S(`[`)
-	//line report/report.qtpl:203
+//line report/report.qtpl:606
+	for i, name := range p.Phases {
+//line report/report.qtpl:607
+		if i > 0 {
+//line report/report.qtpl:607
+			qw422016.N().S(`,`)
+//line report/report.qtpl:607
+		}
+//line report/report.qtpl:607
+		qw422016.N().S(`{name: '`)
+//line report/report.qtpl:609
+		qw422016.N().S(name + " p50")
+//line report/report.qtpl:609
+		if name == "dns" {
+//line report/report.qtpl:609
+			qw422016.N().S(`(cached lookups count as 0)`)
+//line report/report.qtpl:609
+		}
+//line report/report.qtpl:609
+		qw422016.N().S(`',data: [`)
+//line report/report.qtpl:610
+		qw422016.N().S(float64SliceToString(p.PhaseDuration[name]))
+//line report/report.qtpl:610
+		qw422016.N().S(`]}`)
+//line report/report.qtpl:612
+	}
+//line report/report.qtpl:612
+	qw422016.N().S(`]`)
+//line report/report.qtpl:614
+}
+
+//line report/report.qtpl:614
+func (p *Page) writephaseSeries(qq422016 qtio422016.Writer) {
+//line report/report.qtpl:614
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line report/report.qtpl:614
+	p.streamphaseSeries(qw422016)
+//line report/report.qtpl:614
+	qt422016.ReleaseWriter(qw422016)
+//line report/report.qtpl:614
+}
+
+//line report/report.qtpl:614
+func (p *Page) phaseSeries() string {
+//line report/report.qtpl:614
+	qb422016 := qt422016.AcquireByteBuffer()
+//line report/report.qtpl:614
+	p.writephaseSeries(qb422016)
+//line report/report.qtpl:614
+	qs422016 := string(qb422016.B)
+//line report/report.qtpl:614
+	qt422016.ReleaseByteBuffer(qb422016)
+//line report/report.qtpl:614
+	return qs422016
+//line report/report.qtpl:614
+}
+
+//line report/report.qtpl:618
+func (p *Page) streameventStreamSeries(qw422016 *qt422016.Writer) {
+//line report/report.qtpl:618
+	qw422016.N().S(`[`)
+//line report/report.qtpl:620
+	streamquantileSeries(qw422016, "ttfb ", p.EventStreamTTFB)
+//line report/report.qtpl:620
+	qw422016.N().S(`,`)
+//line report/report.qtpl:621
+	streamquantileSeries(qw422016, "gap ", p.EventGap)
+//line report/report.qtpl:621
+	qw422016.N().S(`,`)
+//line report/report.qtpl:622
+	streamquantileSeries(qw422016, "lifetime ", p.EventStreamDuration)
+//line report/report.qtpl:622
+	qw422016.N().S(`]`)
+//line report/report.qtpl:624
+}
+
+//line report/report.qtpl:624
+func (p *Page) writeeventStreamSeries(qq422016 qtio422016.Writer) {
+//line report/report.qtpl:624
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line report/report.qtpl:624
+	p.streameventStreamSeries(qw422016)
+//line report/report.qtpl:624
+	qt422016.ReleaseWriter(qw422016)
+//line report/report.qtpl:624
+}
+
+//line report/report.qtpl:624
+func (p *Page) eventStreamSeries() string {
+//line report/report.qtpl:624
+	qb422016 := qt422016.AcquireByteBuffer()
+//line report/report.qtpl:624
+	p.writeeventStreamSeries(qb422016)
+//line report/report.qtpl:624
+	qs422016 := string(qb422016.B)
+//line report/report.qtpl:624
+	qt422016.ReleaseByteBuffer(qb422016)
+//line report/report.qtpl:624
+	return qs422016
+//line report/report.qtpl:624
+}
+
+//line report/report.qtpl:628
+func streamquantileSeries(qw422016 *qt422016.Writer, prefix string, series map[float64][]float64) {
+//line report/report.qtpl:630
 	var keys []float64
-	for k := range p.RequestDuration {
+	for k := range series {
 		keys = append(keys, k)
 	}
 	sort.Float64s(keys)
 
-	//line report/report.qtpl:209
+//line report/report.qtpl:636
 	for i, k := range keys {
-		//line report/report.qtpl:209
+//line report/report.qtpl:636
 		qw422016.N().S(`{name: '`)
-		//line report/report.qtpl:211
+//line report/report.qtpl:638
+		qw422016.N().S(prefix)
+//line report/report.qtpl:638
 		qw422016.N().F(k)
-		//line report/report.qtpl:211
+//line report/report.qtpl:638
 		qw422016.N().S(`',data: [`)
-		//line report/report.qtpl:212
-		qw422016.N().S(float64SliceToString(p.RequestDuration[k]))
-		//line report/report.qtpl:212
+//line report/report.qtpl:639
+		qw422016.N().S(float64SliceToString(series[k]))
+//line report/report.qtpl:639
 		qw422016.N().S(`],tooltip: {valueSuffix: ' s'}}`)
-		//line report/report.qtpl:215
+//line report/report.qtpl:642
 		if i+1 < len(keys) {
-			//line report/report.qtpl:215
+//line report/report.qtpl:642
 			qw422016.N().S(`,`)
-			//line report/report.qtpl:215
+//line report/report.qtpl:642
 		}
-		//line report/report.qtpl:216
+//line report/report.qtpl:643
 	}
-	//line report/report.qtpl:216
-	qw422016.N().S(`]`)
-//line report/report.qtpl:218
+//line report/report.qtpl:644
 }
 
-//line report/report.qtpl:218
-func (p *Page) writedurationSeries(qq422016 qtio422016.Writer) {
-	//line report/report.qtpl:218
+//line report/report.qtpl:644
+func writequantileSeries(qq422016 qtio422016.Writer, prefix string, series map[float64][]float64) {
+//line report/report.qtpl:644
 	qw422016 := qt422016.AcquireWriter(qq422016)
-	//line report/report.qtpl:218
-	p.streamdurationSeries(qw422016)
-	//line report/report.qtpl:218
+//line report/report.qtpl:644
+	streamquantileSeries(qw422016, prefix, series)
+//line report/report.qtpl:644
 	qt422016.ReleaseWriter(qw422016)
-//line report/report.qtpl:218
+//line report/report.qtpl:644
 }
 
-//line report/report.qtpl:218
-func (p *Page) durationSeries() string {
-	//line report/report.qtpl:218
+//line report/report.qtpl:644
+func quantileSeries(prefix string, series map[float64][]float64) string {
+//line report/report.qtpl:644
 	qb422016 := qt422016.AcquireByteBuffer()
-	//line report/report.qtpl:218
-	p.writedurationSeries(qb422016)
-	//line report/report.qtpl:218
+//line report/report.qtpl:644
+	writequantileSeries(qb422016, prefix, series)
+//line report/report.qtpl:644
 	qs422016 := string(qb422016.B)
-	//line report/report.qtpl:218
+//line report/report.qtpl:644
 	qt422016.ReleaseByteBuffer(qb422016)
-	//line report/report.qtpl:218
+//line report/report.qtpl:644
 	return qs422016
-//line report/report.qtpl:218
+//line report/report.qtpl:644
 }
 
-//line report/report.qtpl:222
+//line report/report.qtpl:648
 func (p *Page) streambytesSeries(qw422016 *qt422016.Writer) {
-	//line report/report.qtpl:222
+//line report/report.qtpl:648
 	qw422016.N().S(`[{name: 'BytesWritten',data: [`)
-	//line report/report.qtpl:225
+//line report/report.qtpl:651
 	qw422016.N().S(float64SliceToString(rate(p.BytesWritten, p.Interval)))
-	//line report/report.qtpl:225
+//line report/report.qtpl:651
 	qw422016.N().S(`]},{name: 'BytesRead',data: [`)
-	//line report/report.qtpl:228
+//line report/report.qtpl:654
 	qw422016.N().S(float64SliceToString(rate(p.BytesRead, p.Interval)))
-	//line report/report.qtpl:228
+//line report/report.qtpl:654
 	qw422016.N().S(`]}]`)
-//line report/report.qtpl:230
+//line report/report.qtpl:656
 }
 
-//line report/report.qtpl:230
+//line report/report.qtpl:656
 func (p *Page) writebytesSeries(qq422016 qtio422016.Writer) {
-	//line report/report.qtpl:230
+//line report/report.qtpl:656
 	qw422016 := qt422016.AcquireWriter(qq422016)
-	//line report/report.qtpl:230
+//line report/report.qtpl:656
 	p.streambytesSeries(qw422016)
-	//line report/report.qtpl:230
+//line report/report.qtpl:656
 	qt422016.ReleaseWriter(qw422016)
-//line report/report.qtpl:230
+//line report/report.qtpl:656
 }
 
-//line report/report.qtpl:230
+//line report/report.qtpl:656
 func (p *Page) bytesSeries() string {
-	//line report/report.qtpl:230
+//line report/report.qtpl:656
 	qb422016 := qt422016.AcquireByteBuffer()
-	//line report/report.qtpl:230
+//line report/report.qtpl:656
 	p.writebytesSeries(qb422016)
-	//line report/report.qtpl:230
+//line report/report.qtpl:656
 	qs422016 := string(qb422016.B)
-	//line report/report.qtpl:230
+//line report/report.qtpl:656
 	qt422016.ReleaseByteBuffer(qb422016)
-	//line report/report.qtpl:230
+//line report/report.qtpl:656
 	return qs422016
-//line report/report.qtpl:230
+//line report/report.qtpl:656
 }
 
-//line report/report.qtpl:234
+//line report/report.qtpl:660
 func (p *Page) streamstatusCodesSeries(qw422016 *qt422016.Writer) {
-	//line report/report.qtpl:234
-	qw422016.N().S(`[{name: 'Status codes',colorByPoint: true,data: [`)
-	//line report/report.qtpl:239
-	for k, v := range p.StatusCodes {
-		//line report/report.qtpl:239
+//line report/report.qtpl:661
+	groups := p.groupStatusCodes()
+
+//line report/report.qtpl:661
+	qw422016.N().S(`[{name: 'Status groups',size: '60%',showInLegend: false,dataLabels: {enabled: true,distance: -30,color: '#ffffff'},data: [`)
+//line report/report.qtpl:672
+	for _, g := range groups {
+//line report/report.qtpl:672
 		qw422016.N().S(`{name: '`)
-		//line report/report.qtpl:241
-		qw422016.N().S(k)
-		//line report/report.qtpl:241
+//line report/report.qtpl:674
+		qw422016.N().S(g.name)
+//line report/report.qtpl:674
+		qw422016.N().S(`',color: '`)
+//line report/report.qtpl:675
+		qw422016.N().S(g.color)
+//line report/report.qtpl:675
 		qw422016.N().S(`',y:`)
-		//line report/report.qtpl:242
-		qw422016.N().FPrec(v, 2)
-		//line report/report.qtpl:242
+//line report/report.qtpl:676
+		qw422016.N().FPrec(g.total, 2)
+//line report/report.qtpl:676
 		qw422016.N().S(`},`)
-		//line report/report.qtpl:244
+//line report/report.qtpl:678
+	}
+//line report/report.qtpl:678
+	qw422016.N().S(`]},{name: 'Status codes',size: '80%',innerSize: '75%',colorByPoint: true,data: [`)
+//line report/report.qtpl:686
+	for _, g := range groups {
+//line report/report.qtpl:687
+		for _, code := range g.codes {
+//line report/report.qtpl:687
+			qw422016.N().S(`{name: '`)
+//line report/report.qtpl:689
+			qw422016.N().S(code)
+//line report/report.qtpl:689
+			qw422016.N().S(`',y:`)
+//line report/report.qtpl:690
+			qw422016.N().FPrec(p.StatusCodes[code], 2)
+//line report/report.qtpl:690
+			qw422016.N().S(`},`)
+//line report/report.qtpl:692
+		}
+//line report/report.qtpl:693
 	}
-	//line report/report.qtpl:244
+//line report/report.qtpl:693
 	qw422016.N().S(`]}]`)
-//line report/report.qtpl:247
+//line report/report.qtpl:696
 }
 
-//line report/report.qtpl:247
+//line report/report.qtpl:696
 func (p *Page) writestatusCodesSeries(qq422016 qtio422016.Writer) {
-	//line report/report.qtpl:247
+//line report/report.qtpl:696
 	qw422016 := qt422016.AcquireWriter(qq422016)
-	//line report/report.qtpl:247
+//line report/report.qtpl:696
 	p.streamstatusCodesSeries(qw422016)
-	//line report/report.qtpl:247
+//line report/report.qtpl:696
 	qt422016.ReleaseWriter(qw422016)
-//line report/report.qtpl:247
+//line report/report.qtpl:696
 }
 
-//line report/report.qtpl:247
+//line report/report.qtpl:696
 func (p *Page) statusCodesSeries() string {
-	//line report/report.qtpl:247
+//line report/report.qtpl:696
 	qb422016 := qt422016.AcquireByteBuffer()
-	//line report/report.qtpl:247
+//line report/report.qtpl:696
 	p.writestatusCodesSeries(qb422016)
-	//line report/report.qtpl:247
+//line report/report.qtpl:696
 	qs422016 := string(qb422016.B)
-	//line report/report.qtpl:247
+//line report/report.qtpl:696
 	qt422016.ReleaseByteBuffer(qb422016)
-	//line report/report.qtpl:247
+//line report/report.qtpl:696
 	return qs422016
-//line report/report.qtpl:247
+//line report/report.qtpl:696
 }
 
-//line report/report.qtpl:250
+//line report/report.qtpl:699
 func (p *Page) streamerrorMessagesTable(qw422016 *qt422016.Writer) {
-	//line report/report.qtpl:250
+//line report/report.qtpl:699
 	qw422016.N().S(`
 	<div style = "float: left; width:50%; height: 400px;">
 	 <!-- IE < 10 does not like giving a tbody a height.  The workaround here applies the scrolling to a wrapped <div>. -->
@@ -783,40 +1788,40 @@ func (p *Page) streamerrorMessagesTable(qw422016 *qt422016.Writer) {
 		 </thead>
 		 <tbody>
 			`)
-	//line report/report.qtpl:265
+//line report/report.qtpl:714
 	for k, v := range p.ErrorMessages {
-		//line report/report.qtpl:265
+//line report/report.qtpl:714
 		qw422016.N().S(`
 				<tr>
 					<td>`)
-		//line report/report.qtpl:267
+//line report/report.qtpl:716
 		qw422016.N().D(v)
-		//line report/report.qtpl:267
+//line report/report.qtpl:716
 		qw422016.N().S(`</td>
 					<td>`)
-		//line report/report.qtpl:268
+//line report/report.qtpl:717
 		qw422016.N().S(k)
-		//line report/report.qtpl:268
+//line report/report.qtpl:717
 		qw422016.N().S(`</td>
 				</tr>
 			`)
-		//line report/report.qtpl:270
+//line report/report.qtpl:719
 	}
-	//line report/report.qtpl:270
+//line report/report.qtpl:719
 	qw422016.N().S(`
 			`)
-	//line report/report.qtpl:271
+//line report/report.qtpl:720
 	if len(p.ErrorMessages) == 0 {
-		//line report/report.qtpl:271
+//line report/report.qtpl:720
 		qw422016.N().S(`
 			<tr>
 				<td></td>
 				<td>No error messages</td>
 			</tr>
             `)
-		//line report/report.qtpl:276
+//line report/report.qtpl:725
 	}
-	//line report/report.qtpl:276
+//line report/report.qtpl:725
 	qw422016.N().S(`
 		 </tbody>
 	 </table>
@@ -825,31 +1830,614 @@ func (p *Page) streamerrorMessagesTable(qw422016 *qt422016.Writer) {
      <!--<![endif]-->
      </div>
 `)
-//line report/report.qtpl:283
+//line report/report.qtpl:732
 }
 
-//line report/report.qtpl:283
+//line report/report.qtpl:732
 func (p *Page) writeerrorMessagesTable(qq422016 qtio422016.Writer) {
-	//line report/report.qtpl:283
+//line report/report.qtpl:732
 	qw422016 := qt422016.AcquireWriter(qq422016)
-	//line report/report.qtpl:283
+//line report/report.qtpl:732
 	p.streamerrorMessagesTable(qw422016)
-	//line report/report.qtpl:283
+//line report/report.qtpl:732
 	qt422016.ReleaseWriter(qw422016)
-//line report/report.qtpl:283
+//line report/report.qtpl:732
 }
 
-//line report/report.qtpl:283
+//line report/report.qtpl:732
 func (p *Page) errorMessagesTable() string {
-	//line report/report.qtpl:283
+//line report/report.qtpl:732
 	qb422016 := qt422016.AcquireByteBuffer()
-	//line report/report.qtpl:283
+//line report/report.qtpl:732
 	p.writeerrorMessagesTable(qb422016)
-	//line report/report.qtpl:283
+//line report/report.qtpl:732
 	qs422016 := string(qb422016.B)
-	//line report/report.qtpl:283
+//line report/report.qtpl:732
 	qt422016.ReleaseByteBuffer(qb422016)
-	//line report/report.qtpl:283
+//line report/report.qtpl:732
 	return qs422016
-//line report/report.qtpl:283
+//line report/report.qtpl:732
+}
+
+//line report/report.qtpl:734
+func (p *Page) streamassertionsTable(qw422016 *qt422016.Writer) {
+//line report/report.qtpl:734
+	qw422016.N().S(`
+	<div style = "clear: both;">
+	 <p class = "title">Failed assertions</p>
+	 <table class="fixed_headers">
+		 <thead>
+			<tr>
+				<td>Count</td>
+				<td>Assertion</td>
+			</tr>
+		 </thead>
+		 <tbody>
+			`)
+//line report/report.qtpl:745
+	for k, v := range p.AssertionFailures {
+//line report/report.qtpl:745
+		qw422016.N().S(`
+				<tr>
+					<td>`)
+//line report/report.qtpl:747
+		qw422016.N().DUL(v)
+//line report/report.qtpl:747
+		qw422016.N().S(`</td>
+					<td>`)
+//line report/report.qtpl:748
+		qw422016.E().S(k)
+//line report/report.qtpl:748
+		qw422016.N().S(`</td>
+				</tr>
+			`)
+//line report/report.qtpl:750
+	}
+//line report/report.qtpl:750
+	qw422016.N().S(`
+		 </tbody>
+	 </table>
+	</div>
+`)
+//line report/report.qtpl:754
+}
+
+//line report/report.qtpl:754
+func (p *Page) writeassertionsTable(qq422016 qtio422016.Writer) {
+//line report/report.qtpl:754
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line report/report.qtpl:754
+	p.streamassertionsTable(qw422016)
+//line report/report.qtpl:754
+	qt422016.ReleaseWriter(qw422016)
+//line report/report.qtpl:754
+}
+
+//line report/report.qtpl:754
+func (p *Page) assertionsTable() string {
+//line report/report.qtpl:754
+	qb422016 := qt422016.AcquireByteBuffer()
+//line report/report.qtpl:754
+	p.writeassertionsTable(qb422016)
+//line report/report.qtpl:754
+	qs422016 := string(qb422016.B)
+//line report/report.qtpl:754
+	qt422016.ReleaseByteBuffer(qb422016)
+//line report/report.qtpl:754
+	return qs422016
+//line report/report.qtpl:754
+}
+
+//line report/report.qtpl:756
+func (p *Page) streamdisconnectsTable(qw422016 *qt422016.Writer) {
+//line report/report.qtpl:756
+	qw422016.N().S(`
+	<div style = "clear: both;">
+	 <p class = "title">WebSocket disconnects</p>
+	 <table class="fixed_headers">
+		 <thead>
+			<tr>
+				<td>Count</td>
+				<td>Reason</td>
+			</tr>
+		 </thead>
+		 <tbody>
+			`)
+//line report/report.qtpl:767
+	for k, v := range p.DisconnectReasons {
+//line report/report.qtpl:767
+		qw422016.N().S(`
+				<tr>
+					<td>`)
+//line report/report.qtpl:769
+		qw422016.N().DUL(v)
+//line report/report.qtpl:769
+		qw422016.N().S(`</td>
+					<td>`)
+//line report/report.qtpl:770
+		qw422016.E().S(k)
+//line report/report.qtpl:770
+		qw422016.N().S(`</td>
+				</tr>
+			`)
+//line report/report.qtpl:772
+	}
+//line report/report.qtpl:772
+	qw422016.N().S(`
+		 </tbody>
+	 </table>
+	</div>
+`)
+//line report/report.qtpl:776
+}
+
+//line report/report.qtpl:776
+func (p *Page) writedisconnectsTable(qq422016 qtio422016.Writer) {
+//line report/report.qtpl:776
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line report/report.qtpl:776
+	p.streamdisconnectsTable(qw422016)
+//line report/report.qtpl:776
+	qt422016.ReleaseWriter(qw422016)
+//line report/report.qtpl:776
+}
+
+//line report/report.qtpl:776
+func (p *Page) disconnectsTable() string {
+//line report/report.qtpl:776
+	qb422016 := qt422016.AcquireByteBuffer()
+//line report/report.qtpl:776
+	p.writedisconnectsTable(qb422016)
+//line report/report.qtpl:776
+	qs422016 := string(qb422016.B)
+//line report/report.qtpl:776
+	qt422016.ReleaseByteBuffer(qb422016)
+//line report/report.qtpl:776
+	return qs422016
+//line report/report.qtpl:776
+}
+
+//line report/report.qtpl:778
+func (p *Page) streamflowTable(qw422016 *qt422016.Writer) {
+//line report/report.qtpl:778
+	qw422016.N().S(`
+	<div style = "clear: both;">
+	 <p class = "title">User flow</p>
+	 <table class="fixed_headers">
+		 <thead>
+			<tr>
+				<td>Step</td>
+				<td>Requests</td>
+				<td>Failures</td>
+				<td>p50, s</td>
+				<td>p99, s</td>
+			</tr>
+		 </thead>
+		 <tbody>
+			`)
+//line report/report.qtpl:792
+	for _, s := range p.FlowSteps {
+//line report/report.qtpl:792
+		qw422016.N().S(`
+				<tr>
+					<td>`)
+//line report/report.qtpl:794
+		qw422016.E().S(s.Name)
+//line report/report.qtpl:794
+		qw422016.N().S(`</td>
+					<td>`)
+//line report/report.qtpl:795
+		qw422016.N().DUL(s.Requests)
+//line report/report.qtpl:795
+		qw422016.N().S(`</td>
+					<td>`)
+//line report/report.qtpl:796
+		qw422016.N().DUL(s.Failures)
+//line report/report.qtpl:796
+		qw422016.N().S(`</td>
+					<td>`)
+//line report/report.qtpl:797
+		qw422016.N().FPrec(s.P50, 4)
+//line report/report.qtpl:797
+		qw422016.N().S(`</td>
+					<td>`)
+//line report/report.qtpl:798
+		qw422016.N().FPrec(s.P99, 4)
+//line report/report.qtpl:798
+		qw422016.N().S(`</td>
+				</tr>
+			`)
+//line report/report.qtpl:800
+	}
+//line report/report.qtpl:800
+	qw422016.N().S(`
+		 </tbody>
+	 </table>
+	</div>
+`)
+//line report/report.qtpl:804
+}
+
+//line report/report.qtpl:804
+func (p *Page) writeflowTable(qq422016 qtio422016.Writer) {
+//line report/report.qtpl:804
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line report/report.qtpl:804
+	p.streamflowTable(qw422016)
+//line report/report.qtpl:804
+	qt422016.ReleaseWriter(qw422016)
+//line report/report.qtpl:804
+}
+
+//line report/report.qtpl:804
+func (p *Page) flowTable() string {
+//line report/report.qtpl:804
+	qb422016 := qt422016.AcquireByteBuffer()
+//line report/report.qtpl:804
+	p.writeflowTable(qb422016)
+//line report/report.qtpl:804
+	qs422016 := string(qb422016.B)
+//line report/report.qtpl:804
+	qt422016.ReleaseByteBuffer(qb422016)
+//line report/report.qtpl:804
+	return qs422016
+//line report/report.qtpl:804
+}
+
+//line report/report.qtpl:806
+func (p *Page) streamipTrafficTable(qw422016 *qt422016.Writer) {
+//line report/report.qtpl:806
+	qw422016.N().S(`
+	<div style = "clear: both;">
+	 <p class = "title">Traffic by IP</p>
+	 <table class="fixed_headers">
+		 <thead>
+			<tr>
+				<td>IP</td>
+				<td>Connections</td>
+				<td>Written, bytes</td>
+				<td>Read, bytes</td>
+			</tr>
+		 </thead>
+		 <tbody>
+			`)
+//line report/report.qtpl:819
+	for _, t := range p.IPTraffic {
+//line report/report.qtpl:819
+		qw422016.N().S(`
+				<tr>
+					<td>`)
+//line report/report.qtpl:821
+		qw422016.E().S(t.IP)
+//line report/report.qtpl:821
+		qw422016.N().S(`</td>
+					<td>`)
+//line report/report.qtpl:822
+		qw422016.N().DUL(t.Connections)
+//line report/report.qtpl:822
+		qw422016.N().S(`</td>
+					<td>`)
+//line report/report.qtpl:823
+		qw422016.N().DUL(t.BytesWritten)
+//line report/report.qtpl:823
+		qw422016.N().S(`</td>
+					<td>`)
+//line report/report.qtpl:824
+		qw422016.N().DUL(t.BytesRead)
+//line report/report.qtpl:824
+		qw422016.N().S(`</td>
+				</tr>
+			`)
+//line report/report.qtpl:826
+	}
+//line report/report.qtpl:826
+	qw422016.N().S(`
+		 </tbody>
+	 </table>
+	</div>
+`)
+//line report/report.qtpl:830
+}
+
+//line report/report.qtpl:830
+func (p *Page) writeipTrafficTable(qq422016 qtio422016.Writer) {
+//line report/report.qtpl:830
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line report/report.qtpl:830
+	p.streamipTrafficTable(qw422016)
+//line report/report.qtpl:830
+	qt422016.ReleaseWriter(qw422016)
+//line report/report.qtpl:830
+}
+
+//line report/report.qtpl:830
+func (p *Page) ipTrafficTable() string {
+//line report/report.qtpl:830
+	qb422016 := qt422016.AcquireByteBuffer()
+//line report/report.qtpl:830
+	p.writeipTrafficTable(qb422016)
+//line report/report.qtpl:830
+	qs422016 := string(qb422016.B)
+//line report/report.qtpl:830
+	qt422016.ReleaseByteBuffer(qb422016)
+//line report/report.qtpl:830
+	return qs422016
+//line report/report.qtpl:830
+}
+
+//line report/report.qtpl:832
+func (p *Page) streamcapacityTable(qw422016 *qt422016.Writer) {
+//line report/report.qtpl:832
+	qw422016.N().S(`
+	<div style = "clear: both;">
+	 <p class = "title">Capacity search. Sustainable QPS: `)
+//line report/report.qtpl:834
+	qw422016.N().FPrec(p.SustainableQps, 2)
+//line report/report.qtpl:834
+	qw422016.N().S(`</p>
+	 <table class="fixed_headers">
+		 <thead>
+			<tr>
+				<td>QPS</td>
+				<td>Achieved</td>
+				<td>p99, s</td>
+				<td>Errors, %</td>
+				<td>SLO</td>
+			</tr>
+		 </thead>
+		 <tbody>
+			`)
+//line report/report.qtpl:846
+	for _, s := range p.Steps {
+//line report/report.qtpl:846
+		qw422016.N().S(`
+				<tr>
+					<td>`)
+//line report/report.qtpl:848
+		qw422016.N().FPrec(s.Qps, 2)
+//line report/report.qtpl:848
+		qw422016.N().S(`</td>
+					<td>`)
+//line report/report.qtpl:849
+		qw422016.N().FPrec(s.Achieved, 2)
+//line report/report.qtpl:849
+		qw422016.N().S(`</td>
+					<td>`)
+//line report/report.qtpl:850
+		qw422016.N().FPrec(s.P99, 4)
+//line report/report.qtpl:850
+		qw422016.N().S(`</td>
+					<td>`)
+//line report/report.qtpl:851
+		qw422016.N().FPrec(s.ErrorRate, 2)
+//line report/report.qtpl:851
+		qw422016.N().S(`</td>
+					<td>`)
+//line report/report.qtpl:852
+		if s.Passed {
+//line report/report.qtpl:852
+			qw422016.N().S(`passed`)
+//line report/report.qtpl:852
+		} else {
+//line report/report.qtpl:852
+			qw422016.N().S(`failed`)
+//line report/report.qtpl:852
+		}
+//line report/report.qtpl:852
+		qw422016.N().S(`</td>
+				</tr>
+			`)
+//line report/report.qtpl:854
+	}
+//line report/report.qtpl:854
+	qw422016.N().S(`
+		 </tbody>
+	 </table>
+	</div>
+`)
+//line report/report.qtpl:858
+}
+
+//line report/report.qtpl:858
+func (p *Page) writecapacityTable(qq422016 qtio422016.Writer) {
+//line report/report.qtpl:858
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line report/report.qtpl:858
+	p.streamcapacityTable(qw422016)
+//line report/report.qtpl:858
+	qt422016.ReleaseWriter(qw422016)
+//line report/report.qtpl:858
+}
+
+//line report/report.qtpl:858
+func (p *Page) capacityTable() string {
+//line report/report.qtpl:858
+	qb422016 := qt422016.AcquireByteBuffer()
+//line report/report.qtpl:858
+	p.writecapacityTable(qb422016)
+//line report/report.qtpl:858
+	qs422016 := string(qb422016.B)
+//line report/report.qtpl:858
+	qt422016.ReleaseByteBuffer(qb422016)
+//line report/report.qtpl:858
+	return qs422016
+//line report/report.qtpl:858
+}
+
+//line report/report.qtpl:861
+func (p *Page) streamdecisionPlotLines(qw422016 *qt422016.Writer) {
+//line report/report.qtpl:861
+	qw422016.N().S(`[`)
+//line report/report.qtpl:863
+	for i, d := range p.Decisions {
+//line report/report.qtpl:863
+		qw422016.N().S(`{value:`)
+//line report/report.qtpl:865
+		qw422016.N().FPrec(d.Time, 2)
+//line report/report.qtpl:865
+		qw422016.N().S(`,width: 1,dashStyle: 'ShortDash',color: '`)
+//line report/report.qtpl:868
+		if strings.Contains(d.Action, "back-off") {
+//line report/report.qtpl:868
+			qw422016.N().S(`#d9534f`)
+//line report/report.qtpl:868
+		} else {
+//line report/report.qtpl:868
+			qw422016.N().S(`#5cb85c`)
+//line report/report.qtpl:868
+		}
+//line report/report.qtpl:868
+		qw422016.N().S(`',label: {text:`)
+//line report/report.qtpl:870
+		qw422016.N().Q(d.Action)
+//line report/report.qtpl:870
+		qw422016.N().S(`,rotation: 90,style: {fontSize: '9px'}}}`)
+//line report/report.qtpl:875
+		if i+1 < len(p.Decisions) {
+//line report/report.qtpl:875
+			qw422016.N().S(`,`)
+//line report/report.qtpl:875
+		}
+//line report/report.qtpl:876
+	}
+//line report/report.qtpl:876
+	qw422016.N().S(`]`)
+//line report/report.qtpl:878
+}
+
+//line report/report.qtpl:878
+func (p *Page) writedecisionPlotLines(qq422016 qtio422016.Writer) {
+//line report/report.qtpl:878
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line report/report.qtpl:878
+	p.streamdecisionPlotLines(qw422016)
+//line report/report.qtpl:878
+	qt422016.ReleaseWriter(qw422016)
+//line report/report.qtpl:878
+}
+
+//line report/report.qtpl:878
+func (p *Page) decisionPlotLines() string {
+//line report/report.qtpl:878
+	qb422016 := qt422016.AcquireByteBuffer()
+//line report/report.qtpl:878
+	p.writedecisionPlotLines(qb422016)
+//line report/report.qtpl:878
+	qs422016 := string(qb422016.B)
+//line report/report.qtpl:878
+	qt422016.ReleaseByteBuffer(qb422016)
+//line report/report.qtpl:878
+	return qs422016
+//line report/report.qtpl:878
+}
+
+//line report/report.qtpl:881
+func (p *Page) streamdecisionsTable(qw422016 *qt422016.Writer) {
+//line report/report.qtpl:881
+	qw422016.N().S(`
+	<div style = "clear: both;">
+	 <p class = "title">Decisions timeline</p>
+	 <table class="fixed_headers">
+		 <thead>
+			<tr>
+				<td>Time, s</td>
+				<td>Phase</td>
+				<td>Action</td>
+				<td>QPS</td>
+				<td>Workers</td>
+				<td>Multiplier</td>
+				<td>Errors</td>
+				<td>Overflow</td>
+				<td>Reason</td>
+			</tr>
+		 </thead>
+		 <tbody>
+			`)
+//line report/report.qtpl:899
+	for _, d := range p.Decisions {
+//line report/report.qtpl:899
+		qw422016.N().S(`
+				<tr>
+					<td>`)
+//line report/report.qtpl:901
+		qw422016.N().FPrec(d.Time, 1)
+//line report/report.qtpl:901
+		qw422016.N().S(`</td>
+					<td>`)
+//line report/report.qtpl:902
+		qw422016.E().S(d.Phase)
+//line report/report.qtpl:902
+		qw422016.N().S(`</td>
+					<td>`)
+//line report/report.qtpl:903
+		qw422016.E().S(d.Action)
+//line report/report.qtpl:903
+		qw422016.N().S(`</td>
+					<td>`)
+//line report/report.qtpl:904
+		qw422016.N().FPrec(d.Qps, 2)
+//line report/report.qtpl:904
+		qw422016.N().S(`</td>
+					<td>`)
+//line report/report.qtpl:905
+		qw422016.N().D(d.Workers)
+//line report/report.qtpl:905
+		qw422016.N().S(`</td>
+					<td>`)
+//line report/report.qtpl:906
+		qw422016.N().FPrec(d.Multiplier, 4)
+//line report/report.qtpl:906
+		qw422016.N().S(`</td>
+					<td>`)
+//line report/report.qtpl:907
+		qw422016.N().DUL(d.Errors)
+//line report/report.qtpl:907
+		qw422016.N().S(`</td>
+					<td>`)
+//line report/report.qtpl:908
+		qw422016.N().D(d.Overflow)
+//line report/report.qtpl:908
+		qw422016.N().S(`</td>
+					<td>`)
+//line report/report.qtpl:909
+		qw422016.E().S(d.Reason)
+//line report/report.qtpl:909
+		qw422016.N().S(`</td>
+				</tr>
+			`)
+//line report/report.qtpl:911
+	}
+//line report/report.qtpl:911
+	qw422016.N().S(`
+		 </tbody>
+	 </table>
+	</div>
+`)
+//line report/report.qtpl:915
+}
+
+//line report/report.qtpl:915
+func (p *Page) writedecisionsTable(qq422016 qtio422016.Writer) {
+//line report/report.qtpl:915
+	qw422016 := qt422016.AcquireWriter(qq422016)
+//line report/report.qtpl:915
+	p.streamdecisionsTable(qw422016)
+//line report/report.qtpl:915
+	qt422016.ReleaseWriter(qw422016)
+//line report/report.qtpl:915
+}
+
+//line report/report.qtpl:915
+func (p *Page) decisionsTable() string {
+//line report/report.qtpl:915
+	qb422016 := qt422016.AcquireByteBuffer()
+//line report/report.qtpl:915
+	p.writedecisionsTable(qb422016)
+//line report/report.qtpl:915
+	qs422016 := string(qb422016.B)
+//line report/report.qtpl:915
+	qt422016.ReleaseByteBuffer(qb422016)
+//line report/report.qtpl:915
+	return qs422016
+//line report/report.qtpl:915
 }
@@ -25,6 +25,17 @@ func float64SliceToString(sl []float64) string {
 	return strings.Join(str[:], ",")
 }
 
+// appendQuantiles appends values of quantiles to corresponding series
+// series map is created if needed
+func appendQuantiles(series *map[float64][]float64, q map[float64]float64) {
+	if *series == nil {
+		*series = make(map[float64][]float64)
+	}
+	for k, v := range q {
+		(*series)[k] = append((*series)[k], v)
+	}
+}
+
 // rate calculate difference between current and previous value
 func rate(sl []uint64, step float64) []float64 {
 	result := make([]float64, len(sl))
@@ -46,6 +57,22 @@ func rate(sl []uint64, step float64) []float64 {
 	return result
 }
 
+// reuseRatio calculates percent of requests
+// which were sent over already established connections
+func reuseRatio(requests, conns []uint64, step float64) []float64 {
+	reqRate, connRate := rate(requests, step), rate(conns, step)
+	result := make([]float64, len(reqRate))
+	for i, r := range reqRate {
+		if r == 0 || i >= len(connRate) {
+			continue
+		}
+		if v := (r - connRate[i]) / r * 100; v > 0 {
+			result[i] = v
+		}
+	}
+	return result
+}
+
 func mustPwd() string {
 	pwd, err := os.Getwd()
 	if err != nil {
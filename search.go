@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/hagen1778/fasthttploader/report"
+)
+
+// Max number of steps while increasing QPS, to prevent endless search
+// against server which never breaks SLO
+const searchMaxSteps = 30
+
+// searchAchievedRatio is a min ratio of achieved QPS to QPS of step,
+// at which step could be passed. Workers which can't keep up
+// with the rate limit would hide the real latency otherwise
+const searchAchievedRatio = 0.9
+
+// searchCapacity steps QPS up by searchStep until SLO is broken,
+// then bisects the range between last passed and first failed steps.
+// The highest passed QPS is used as sustainable rate for load phase.
+// Error is returned if none of steps has passed.
+func searchCapacity(cfg *loadConfig) error {
+	cfg.c = *c
+	passed := findCapacity(float64(*searchStart), *searchStep, *searchRefine, func(qps float64) bool {
+		return runSearchStep(qps, cfg)
+	})
+	if passed == 0 {
+		return fmt.Errorf("capacity search wasn't able to find QPS which satisfies SLO")
+	}
+	cfg.qps = passed
+	r.Lock()
+	r.SustainableQps = passed
+	r.Unlock()
+	fmt.Printf("Sustainable QPS: %.2f; Workers: %d\n\n", cfg.qps, cfg.c)
+	return nil
+}
+
+// findCapacity returns the highest QPS for which step returns true.
+// QPS is multiplied by 1+stepCoef starting from start till the first failed step
+// or searchMaxSteps, then the range between last passed and first failed QPS
+// is bisected refine times or till it becomes narrower than 1 QPS.
+// Zero is returned if none of steps has passed
+func findCapacity(start, stepCoef float64, refine int, step func(qps float64) bool) float64 {
+	var passed, failed float64
+	qps := start
+	for i := 0; i < searchMaxSteps; i++ {
+		if !step(qps) {
+			failed = qps
+			break
+		}
+		passed = qps
+		qps *= 1 + stepCoef
+	}
+
+	if failed > 0 {
+		for i := 0; i < refine; i++ {
+			qps = (passed + failed) / 2
+			if qps-passed < 1 {
+				break
+			}
+			if step(qps) {
+				passed = qps
+			} else {
+				failed = qps
+			}
+		}
+	}
+	return passed
+}
+
+// runSearchStep holds given QPS during searchHold and reports
+// whether QPS was achieved with p99 latency and errors rate within SLO
+func runSearchStep(qps float64, cfg *loadConfig) bool {
+	client = newClient()
+	startTime := time.Now()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	throttle.SetLimit(qps)
+	client.RunWorkers(cfg.c)
+	go func() {
+		timeout := time.After(*searchHold)
+		sampler := time.Tick(*samplePeriod)
+		bar, progressTicker := acquireProgressBar(*searchHold)
+		for {
+			select {
+			case <-timeout:
+				finishProgressBar(bar)
+				cfg.c = client.Amount()
+				cancel()
+				return
+			case <-progressTicker:
+				bar.Increment()
+			case <-sampler:
+				printState()
+				// not enough workers to keep up with the limit
+				if client.Overflow() > 0 {
+					client.RunWorkers(int(float64(client.Amount()) * multiplier))
+				}
+			}
+		}
+	}()
+	load(ctx)
+
+	step := report.Step{
+		Qps:      qps,
+		Achieved: float64(client.RequestSum()) / time.Since(startTime).Seconds(),
+		P99:      client.RequestDuration()[0.99],
+	}
+	if sum := client.RequestSum(); sum > 0 {
+		step.ErrorRate = float64(client.Errors()) / float64(sum) * 100
+	}
+	step.Passed = !math.IsNaN(step.P99) && step.P99 <= sloLatency.Seconds() && step.ErrorRate <= *sloErrors &&
+		step.Achieved >= searchAchievedRatio*qps
+	printSummary(fmt.Sprintf("Capacity step %.2f QPS", qps), startTime)
+	// wait for in-flight requests, so they won't affect next step metrics
+	client.Flush()
+
+	r.Lock()
+	r.Steps = append(r.Steps, step)
+	r.Unlock()
+	return step.Passed
+}
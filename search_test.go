@@ -0,0 +1,92 @@
+package main
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestFindCapacity(t *testing.T) {
+	testCases := []struct {
+		name     string
+		start    float64
+		stepCoef float64
+		refine   int
+		capacity float64
+		expected float64
+		steps    []float64
+	}{
+		{
+			name:     "step up and bisect",
+			start:    100,
+			stepCoef: 0.5,
+			refine:   3,
+			capacity: 300,
+			expected: 295.3125,
+			steps:    []float64{100, 150, 225, 337.5, 281.25, 309.375, 295.3125},
+		},
+		{
+			name:     "no refine",
+			start:    100,
+			stepCoef: 1,
+			refine:   0,
+			capacity: 300,
+			expected: 200,
+			steps:    []float64{100, 200, 400},
+		},
+		{
+			name:     "refine stops at 1 QPS range",
+			start:    8,
+			stepCoef: 0.25,
+			refine:   5,
+			capacity: 11.5,
+			expected: 11.25,
+			steps:    []float64{8, 10, 12.5, 11.25},
+		},
+		{
+			name:     "first step fails",
+			start:    100,
+			stepCoef: 0.5,
+			refine:   3,
+			capacity: 60,
+			expected: 50,
+			steps:    []float64{100, 50, 75, 62.5},
+		},
+		{
+			name:     "nothing passes",
+			start:    100,
+			stepCoef: 0.5,
+			refine:   2,
+			capacity: 0,
+			expected: 0,
+			steps:    []float64{100, 50, 25},
+		},
+	}
+	for _, tc := range testCases {
+		var steps []float64
+		got := findCapacity(tc.start, tc.stepCoef, tc.refine, func(qps float64) bool {
+			steps = append(steps, qps)
+			return qps <= tc.capacity
+		})
+		if got != tc.expected {
+			t.Errorf("%s: unexpected capacity. Got: %f; Expected: %f", tc.name, got, tc.expected)
+		}
+		if !reflect.DeepEqual(steps, tc.steps) {
+			t.Errorf("%s: unexpected steps. Got: %v; Expected: %v", tc.name, steps, tc.steps)
+		}
+	}
+}
+
+func TestFindCapacityMaxSteps(t *testing.T) {
+	var n int
+	got := findCapacity(1, 1, 3, func(qps float64) bool {
+		n++
+		return true
+	})
+	if n != searchMaxSteps {
+		t.Errorf("Unexpected number of steps. Got: %d; Expected: %d", n, searchMaxSteps)
+	}
+	if expected := math.Pow(2, searchMaxSteps-1); got != expected {
+		t.Errorf("Unexpected capacity. Got: %f; Expected: %f", got, expected)
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hagen1778/fasthttploader/fastclient"
+)
+
+// latencyTarget is a limit for latency quantile
+type latencyTarget struct {
+	quantile float64
+	limit    time.Duration
+}
+
+func (lt latencyTarget) String() string {
+	return fmt.Sprintf("p%s:%s", strconv.FormatFloat(lt.quantile*100, 'f', -1, 64), lt.limit)
+}
+
+// latencyTargets implements flag.Value for list of targets
+// in form of "p95:100ms,p99:300ms"
+type latencyTargets []latencyTarget
+
+func (lts *latencyTargets) String() string {
+	var str []string
+	for _, lt := range *lts {
+		str = append(str, lt.String())
+	}
+	return strings.Join(str, ",")
+}
+
+func (lts *latencyTargets) Set(value string) error {
+	for _, v := range strings.Split(value, ",") {
+		tmp := strings.SplitN(strings.TrimSpace(v), ":", 2)
+		if len(tmp) != 2 || !strings.HasPrefix(tmp[0], "p") {
+			return fmt.Errorf("cannot parse latency target %q; expected form is p95:100ms", v)
+		}
+		p, err := strconv.ParseFloat(tmp[0][1:], 64)
+		if err != nil {
+			return fmt.Errorf("cannot parse quantile of latency target %q: %s", v, err)
+		}
+		if !isSupportedQuantile(p / 100) {
+			return fmt.Errorf("unsupported quantile %q; supported quantiles are %v", tmp[0], fastclient.Quantiles())
+		}
+		limit, err := time.ParseDuration(tmp[1])
+		if err != nil {
+			return fmt.Errorf("cannot parse limit of latency target %q: %s", v, err)
+		}
+		*lts = append(*lts, latencyTarget{quantile: p / 100, limit: limit})
+	}
+	return nil
+}
+
+func isSupportedQuantile(q float64) bool {
+	for _, v := range fastclient.Quantiles() {
+		if v == q {
+			return true
+		}
+	}
+	return false
+}
+
+// exceededLatency returns first target which is exceeded by recent latency,
+// which is passed as map quantile:seconds
+func exceededLatency(lts latencyTargets, d map[float64]float64) (latencyTarget, float64, bool) {
+	for _, lt := range lts {
+		if v := d[lt.quantile]; v > lt.limit.Seconds() {
+			return lt, v, true
+		}
+	}
+	return latencyTarget{}, 0, false
+}
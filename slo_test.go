@@ -0,0 +1,114 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLatencyTargets(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected latencyTargets
+		str      string
+	}{
+		{
+			value:    "p95:100ms",
+			expected: latencyTargets{{quantile: 0.95, limit: 100 * time.Millisecond}},
+			str:      "p95:100ms",
+		},
+		{
+			value: "p95:100ms, p99:1s",
+			expected: latencyTargets{
+				{quantile: 0.95, limit: 100 * time.Millisecond},
+				{quantile: 0.99, limit: time.Second},
+			},
+			str: "p95:100ms,p99:1s",
+		},
+		{
+			value:    "p50:1.5s",
+			expected: latencyTargets{{quantile: 0.5, limit: 1500 * time.Millisecond}},
+			str:      "p50:1.5s",
+		},
+	}
+	for _, tc := range testCases {
+		var lts latencyTargets
+		if err := lts.Set(tc.value); err != nil {
+			t.Errorf("Unexpected error for %q: %s", tc.value, err)
+			continue
+		}
+		if !reflect.DeepEqual(lts, tc.expected) {
+			t.Errorf("Unexpected targets for %q. Got: %v; Expected: %v", tc.value, lts, tc.expected)
+		}
+		if str := lts.String(); str != tc.str {
+			t.Errorf("Unexpected string for %q. Got: %q; Expected: %q", tc.value, str, tc.str)
+		}
+	}
+
+	for _, v := range []string{
+		"",
+		"p95",
+		"95:100ms",
+		"p95<100ms",
+		"pxx:100ms",
+		"p42:100ms",
+		"p95:100",
+		"p95:fast",
+		"p95:100ms,p99",
+	} {
+		var lts latencyTargets
+		if err := lts.Set(v); err == nil {
+			t.Errorf("Expected error for %q", v)
+		}
+	}
+}
+
+func TestExceededLatency(t *testing.T) {
+	var lts latencyTargets
+	if err := lts.Set("p95:100ms,p99:300ms"); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	testCases := []struct {
+		name     string
+		d        map[float64]float64
+		expected string
+		v        float64
+	}{
+		{
+			name: "no latency",
+			d:    map[float64]float64{},
+		},
+		{
+			name: "within targets",
+			d:    map[float64]float64{0.95: 0.1, 0.99: 0.3},
+		},
+		{
+			name:     "first target exceeded",
+			d:        map[float64]float64{0.95: 0.2, 0.99: 0.2},
+			expected: "p95:100ms",
+			v:        0.2,
+		},
+		{
+			name:     "second target exceeded",
+			d:        map[float64]float64{0.95: 0.05, 0.99: 0.5},
+			expected: "p99:300ms",
+			v:        0.5,
+		},
+		{
+			name:     "both targets exceeded",
+			d:        map[float64]float64{0.95: 0.2, 0.99: 0.5},
+			expected: "p95:100ms",
+			v:        0.2,
+		},
+	}
+	for _, tc := range testCases {
+		lt, v, ok := exceededLatency(lts, tc.d)
+		if ok != (tc.expected != "") {
+			t.Errorf("%s: unexpected result. Got: %v; Expected: %v", tc.name, ok, !ok)
+			continue
+		}
+		if ok && (lt.String() != tc.expected || v != tc.v) {
+			t.Errorf("%s: unexpected target. Got: %s %f; Expected: %s %f", tc.name, lt, v, tc.expected, tc.v)
+		}
+	}
+}